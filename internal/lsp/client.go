@@ -4,11 +4,13 @@ import (
 	"bufio"
 	"context"
 	"encoding/json" // Keep for potential future use within client.go
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"os"
 	"os/exec"
+	"sort"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -25,6 +27,9 @@ type Client struct {
 	stdout *bufio.Reader
 	stderr io.ReadCloser
 
+	// Serializes writes so that concurrent messages do not interleave
+	writeMu sync.Mutex
+
 	// Request ID counter
 	nextID atomic.Int32
 
@@ -44,14 +49,65 @@ type Client struct {
 	diagnostics   map[protocol.DocumentUri][]protocol.Diagnostic // Use protocol types
 	diagnosticsMu sync.RWMutex
 
+	// Document version each cached diagnostics entry belongs to, and the callers
+	// waiting for diagnostics of a version. Guarded by diagnosticsMu.
+	diagnosticVersions map[protocol.DocumentUri]int32
+	diagnosticWaiters  map[protocol.DocumentUri][]*diagnosticWaiter
+
+	// How long WaitForDiagnostics waits for published diagnostics
+	diagnosticsTimeout time.Duration
+
+	// Detects the language ID sent when opening files, by extension if nil
+	languageDetector *LanguageDetector
+
 	// Files are currently opened by the LSP
 	openFiles   map[string]*OpenFileInfo
 	openFilesMu sync.RWMutex
 
+	// Capabilities reported by the server in its initialize response
+	capabilities   protocol.ServerCapabilities
+	capabilitiesMu sync.RWMutex
+
+	// Whether the client asked the server to version published diagnostics
+	diagnosticVersionSupport bool
+
+	// Closed when the connection to the server is lost, e.g. because the
+	// process exited. connErr records why.
+	done     chan struct{}
+	doneOnce sync.Once
+	connErr  error
+
+	// Closed once the process has exited and the output it wrote before has
+	// been read. waitErr is its exit status as Cmd.Wait would report it.
+	exited  chan struct{}
+	waitErr error
+
+	// Closed when handleMessages stops reading stdout
+	readDone chan struct{}
+
 	// Debug flag
 	debug bool
 }
 
+// ErrServerExited is returned for requests to a server whose connection was
+// lost, e.g. because the process exited or crashed.
+var ErrServerExited = errors.New("language server exited")
+
+// exitOutputTimeout is how long the output of a process that exited is read
+// before the exit is reported, in case a child process keeps stdout open.
+const exitOutputTimeout = 500 * time.Millisecond
+
+// DefaultDiagnosticsTimeout is how long WaitForDiagnostics waits for servers
+// without pull diagnostics to publish diagnostics, unless configured otherwise.
+const DefaultDiagnosticsTimeout = 5 * time.Second
+
+// diagnosticWaiter is woken when diagnostics for version or a later version
+// of a document are published.
+type diagnosticWaiter struct {
+	version int32
+	done    chan struct{}
+}
+
 // OpenFileInfo stores information about an open file.
 type OpenFileInfo struct {
 	Version int32
@@ -85,24 +141,18 @@ func NewClient(command string, args ...string) (*Client, error) {
 		return nil, fmt.Errorf("failed to create stderr pipe: %w", err)
 	}
 
-	client := &Client{
-		Cmd:                   cmd,
-		stdin:                 stdin,
-		stdout:                bufio.NewReader(stdout),
-		stderr:                stderr,
-		handlers:              make(map[int32]chan *Message), // Use Message from this package
-		notificationHandlers:  make(map[string]NotificationHandler), // Use NotificationHandler from transport.go
-		serverRequestHandlers: make(map[string]ServerRequestHandler), // Use ServerRequestHandler from transport.go
-		diagnostics:           make(map[protocol.DocumentUri][]protocol.Diagnostic), // Use protocol types
-		openFiles:             make(map[string]*OpenFileInfo),
-		debug:                 os.Getenv("MCP_LSP_DEBUG") == "true",
-	}
+	client := newClient(stdin, stdout)
+	client.Cmd = cmd
+	client.stderr = stderr
+	client.exited = make(chan struct{})
 
 	if err := cmd.Start(); err != nil {
 		return nil, fmt.Errorf("failed to start LSP server: %w", err)
 	}
 
+	stderrDone := make(chan struct{})
 	go func() {
+		defer close(stderrDone)
 		scanner := bufio.NewScanner(stderr)
 		for scanner.Scan() {
 			fmt.Fprintf(os.Stderr, "LSP Server: %s\n", scanner.Text())
@@ -112,12 +162,96 @@ func NewClient(command string, args ...string) (*Client, error) {
 		}
 	}()
 
+	// The process can exit while its stdout stays open, e.g. when a wrapper
+	// such as npx leaves it to a child, so exits are detected by waiting for
+	// the process. Unlike Cmd.Wait, Process.Wait leaves the pipes open, so
+	// what the process wrote before exiting is still read.
+	go func() {
+		state, err := cmd.Process.Wait()
+		if err == nil && !state.Success() {
+			err = &exec.ExitError{ProcessState: state}
+		}
+
+		select {
+		case <-client.readDone:
+		case <-time.After(exitOutputTimeout):
+			// A child of the process keeps stdout open
+		}
+		client.waitErr = err
+		close(client.exited)
+		if err != nil {
+			client.disconnect(fmt.Errorf("process exited: %w", err))
+		} else {
+			client.disconnect(errors.New("process exited"))
+		}
+
+		// The pipes are released once nothing reads them anymore
+		<-client.readDone
+		<-stderrDone
+		stdout.Close()
+		stderr.Close()
+	}()
+
 	// handleMessages is defined in transport.go, start it here
 	go client.handleMessages()
 
 	return client, nil
 }
 
+// NewStreamClient creates a client that talks to a language server over the
+// given streams instead of starting a process, e.g. to a server running in the
+// same process in tests.
+func NewStreamClient(stdout io.Reader, stdin io.WriteCloser) *Client {
+	client := newClient(stdin, stdout)
+	go client.handleMessages()
+	return client
+}
+
+// newClient creates a client that writes to stdin and reads from stdout.
+func newClient(stdin io.WriteCloser, stdout io.Reader) *Client {
+	return &Client{
+		stdin:                 stdin,
+		stdout:                bufio.NewReader(stdout),
+		handlers:              make(map[int32]chan *Message),                        // Use Message from this package
+		notificationHandlers:  make(map[string]NotificationHandler),                 // Use NotificationHandler from transport.go
+		serverRequestHandlers: make(map[string]ServerRequestHandler),                // Use ServerRequestHandler from transport.go
+		diagnostics:           make(map[protocol.DocumentUri][]protocol.Diagnostic), // Use protocol types
+		diagnosticVersions:    make(map[protocol.DocumentUri]int32),
+		diagnosticWaiters:     make(map[protocol.DocumentUri][]*diagnosticWaiter),
+		diagnosticsTimeout:    DefaultDiagnosticsTimeout,
+		openFiles:             make(map[string]*OpenFileInfo),
+		done:                  make(chan struct{}),
+		readDone:              make(chan struct{}),
+		debug:                 os.Getenv("MCP_LSP_DEBUG") == "true",
+	}
+}
+
+// Done returns a channel that is closed when the connection to the server is
+// lost, e.g. because the process exited or a write hit a broken pipe.
+func (c *Client) Done() <-chan struct{} {
+	return c.done
+}
+
+// Err returns why the connection to the server was lost, or nil while it is
+// alive. The error wraps ErrServerExited.
+func (c *Client) Err() error {
+	select {
+	case <-c.done:
+		return c.connErr
+	default:
+		return nil
+	}
+}
+
+// disconnect records that the connection to the server was lost and wakes
+// every caller waiting for a response. Only the first cause is kept.
+func (c *Client) disconnect(cause error) {
+	c.doneOnce.Do(func() {
+		c.connErr = fmt.Errorf("%w: %v", ErrServerExited, cause)
+		close(c.done)
+	})
+}
+
 // RegisterNotificationHandler registers a handler for a specific notification method.
 // Assumes NotificationHandler type is defined in transport.go
 func (c *Client) RegisterNotificationHandler(method string, handler NotificationHandler) {
@@ -193,9 +327,58 @@ func (c *Client) InitializeLSPClient(ctx context.Context, workspaceDir string) (
 						WillSaveWaitUntil:   false, // bool
 						DidSave:             true,  // bool
 					},
+					Hover: &protocol.HoverClientCapabilities{
+						ContentFormat: []protocol.MarkupKind{protocol.Markdown, protocol.PlainText},
+					},
+					Completion: protocol.CompletionClientCapabilities{
+						CompletionItem: protocol.ClientCompletionItemOptions{
+							SnippetSupport:      false,
+							DocumentationFormat: []protocol.MarkupKind{protocol.Markdown, protocol.PlainText},
+							DeprecatedSupport:   true,
+							PreselectSupport:    true,
+							ResolveSupport: &protocol.ClientCompletionItemResolveOptions{
+								Properties: []string{"documentation", "detail"},
+							},
+							LabelDetailsSupport: true,
+						},
+						ContextSupport: true,
+					},
+					InlayHint: &protocol.InlayHintClientCapabilities{
+						ResolveSupport: &protocol.ClientInlayHintResolveOptions{
+							Properties: []string{"tooltip", "label.tooltip"},
+						},
+					},
+					SemanticTokens: protocol.SemanticTokensClientCapabilities{
+						Requests: protocol.ClientSemanticTokensRequestOptions{
+							Range: &protocol.Or_ClientSemanticTokensRequestOptions_range{Value: true},
+							Full:  &protocol.Or_ClientSemanticTokensRequestOptions_full{Value: true},
+						},
+						TokenTypes:     semanticTokenTypes,
+						TokenModifiers: semanticTokenModifiers,
+						Formats:        []protocol.TokenFormat{protocol.Relative},
+					},
+					FoldingRange: &protocol.FoldingRangeClientCapabilities{
+						LineFoldingOnly: true,
+					},
+					SelectionRange: &protocol.SelectionRangeClientCapabilities{},
+					// Enables textDocument/diagnostic and workspace/diagnostic pulls
+					Diagnostic: &protocol.DiagnosticClientCapabilities{},
+					DocumentLink: &protocol.DocumentLinkClientCapabilities{
+						TooltipSupport: true,
+					},
+					SignatureHelp: &protocol.SignatureHelpClientCapabilities{
+						SignatureInformation: &protocol.ClientSignatureInformationOptions{
+							DocumentationFormat: []protocol.MarkupKind{protocol.Markdown, protocol.PlainText},
+							// Parameter labels are rendered as strings, offsets are not supported
+							ParameterInformation: &protocol.ClientSignatureParameterInformationOptions{
+								LabelOffsetSupport: false,
+							},
+							ActiveParameterSupport: true,
+						},
+					},
 					Rename: &protocol.RenameClientCapabilities{
 						DynamicRegistration: false, // bool
-						PrepareSupport:      false, // bool
+						PrepareSupport:      true,  // bool
 					},
 					// Corrected: DocumentSymbol is protocol.DocumentSymbolClientCapabilities (not pointer)
 					DocumentSymbol: protocol.DocumentSymbolClientCapabilities{
@@ -206,13 +389,38 @@ func (c *Client) InitializeLSPClient(ctx context.Context, workspaceDir string) (
 							ValueSet: symbolKinds,
 						},
 					},
+					CodeAction: protocol.CodeActionClientCapabilities{
+						// Without literal support servers may return plain commands only
+						CodeActionLiteralSupport: protocol.ClientCodeActionLiteralOptions{
+							CodeActionKind: protocol.ClientCodeActionKindOptions{
+								ValueSet: []protocol.CodeActionKind{
+									protocol.QuickFix,
+									protocol.Refactor,
+									protocol.RefactorExtract,
+									protocol.RefactorInline,
+									protocol.RefactorMove,
+									protocol.RefactorRewrite,
+									protocol.Source,
+									protocol.SourceOrganizeImports,
+									protocol.SourceFixAll,
+								},
+							},
+						},
+						IsPreferredSupport: true,
+						DisabledSupport:    true,
+						DataSupport:        true,
+						// Edits of actions returned without one are filled in by codeAction/resolve
+						ResolveSupport: &protocol.ClientCodeActionResolveOptions{
+							Properties: []string{"edit"},
+						},
+					},
 					CodeLens: &protocol.CodeLensClientCapabilities{
 						// DynamicRegistration: Ptr(true), // Check protocol.go
 					},
 					// Corrected: PublishDiagnostics is protocol.PublishDiagnosticsClientCapabilities (not pointer)
 					PublishDiagnostics: protocol.PublishDiagnosticsClientCapabilities{
-						// VersionSupport is bool
-						VersionSupport: false,
+						// Versions let WaitForDiagnostics tell fresh diagnostics from stale ones
+						VersionSupport: true,
 						// Initialize embedded DiagnosticsCapabilities fields
 						DiagnosticsCapabilities: protocol.DiagnosticsCapabilities{
 							RelatedInformation: false, // bool
@@ -233,6 +441,15 @@ func (c *Client) InitializeLSPClient(ctx context.Context, workspaceDir string) (
 					"vendor":             true,
 					"vulncheck":          false,
 				},
+				"semanticTokens": true,
+				"hints": map[string]bool{
+					"assignVariableTypes":    true,
+					"compositeLiteralFields": true,
+					"constantValues":         true,
+					"functionTypeParameters": true,
+					"parameterNames":         true,
+					"rangeVariableTypes":     true,
+				},
 			},
 			// Corrected: Trace field is *protocol.TraceValue
 			Trace: tracePtr,
@@ -246,6 +463,11 @@ func (c *Client) InitializeLSPClient(ctx context.Context, workspaceDir string) (
 		return nil, fmt.Errorf("initialize failed: %w", err)
 	}
 
+	c.capabilitiesMu.Lock()
+	c.capabilities = result.Capabilities
+	c.diagnosticVersionSupport = initParams.Capabilities.TextDocument.PublishDiagnostics.VersionSupport
+	c.capabilitiesMu.Unlock()
+
 	// Initialized is defined in methods.go
 	if err := c.Initialized(ctx, protocol.InitializedParams{}); err != nil {
 		return nil, fmt.Errorf("initialized notification failed: %w", err)
@@ -264,6 +486,35 @@ func (c *Client) InitializeLSPClient(ctx context.Context, workspaceDir string) (
 }
 
 
+// ServerCapabilities returns the capabilities the server reported when it was
+// initialized.
+func (c *Client) ServerCapabilities() protocol.ServerCapabilities {
+	c.capabilitiesMu.RLock()
+	defer c.capabilitiesMu.RUnlock()
+	return c.capabilities
+}
+
+// supportsDiagnosticVersions reports whether the client advertised version
+// support for published diagnostics.
+func (c *Client) supportsDiagnosticVersions() bool {
+	c.capabilitiesMu.RLock()
+	defer c.capabilitiesMu.RUnlock()
+	return c.diagnosticVersionSupport
+}
+
+// The standard semantic token types and modifiers from the LSP specification.
+var (
+	semanticTokenTypes = []string{
+		"namespace", "type", "class", "enum", "interface", "struct", "typeParameter", "parameter",
+		"variable", "property", "enumMember", "event", "function", "method", "macro", "keyword",
+		"modifier", "comment", "string", "number", "regexp", "operator", "decorator",
+	}
+	semanticTokenModifiers = []string{
+		"declaration", "definition", "readonly", "static", "deprecated", "abstract", "async",
+		"modification", "documentation", "defaultLibrary",
+	}
+)
+
 // Close sends shutdown and exit messages to the LSP server and waits for it to terminate.
 func (c *Client) Close() error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -290,6 +541,7 @@ func (c *Client) Close() error {
 	}
 
 	// Close stdin pipe after sending exit
+	c.writeMu.Lock()
 	if c.stdin != nil {
 		if err := c.stdin.Close(); err != nil {
 			if c.debug {
@@ -298,26 +550,23 @@ func (c *Client) Close() error {
 		}
 		c.stdin = nil
 	}
+	c.disconnect(errors.New("client closed"))
+	c.writeMu.Unlock()
 
-	// Wait for the process to exit
-	done := make(chan error, 1)
-	go func() {
-		if c.Cmd != nil && c.Cmd.Process != nil {
-			done <- c.Cmd.Wait()
-		} else {
-			done <- fmt.Errorf("process already exited or not started")
-		}
-	}()
-
+	// Wait for the process to exit, as observed by the goroutine started in
+	// NewClient
+	if c.exited == nil {
+		return nil // Not a process started by NewClient
+	}
 	select {
-	case err := <-done:
-		if exitErr, ok := err.(*exec.ExitError); ok {
+	case <-c.exited:
+		if exitErr, ok := c.waitErr.(*exec.ExitError); ok {
 			if c.debug {
 				log.Printf("LSP process exited with status: %s", exitErr.Error())
 			}
 			return nil // Expected exit after shutdown/exit
 		}
-		return err // Other wait error
+		return c.waitErr // Other wait error
 	case <-time.After(2 * time.Second):
 		// Timeout waiting for exit, kill the process
 		if c.Cmd != nil && c.Cmd.Process != nil {
@@ -393,7 +642,7 @@ func (c *Client) OpenFile(ctx context.Context, filepath string) error {
 	params := protocol.DidOpenTextDocumentParams{
 		TextDocument: protocol.TextDocumentItem{
 			URI:        protocol.DocumentUri(uri),
-			LanguageID: DetectLanguageID(uri), // Assign protocol.LanguageKind directly
+			LanguageID: c.languageID(filepath, content),
 			Version:    1,
 			Text:       string(content),
 		},
@@ -423,8 +672,6 @@ func (c *Client) OpenFile(ctx context.Context, filepath string) error {
 
 // NotifyChange notifies the LSP server that a file has changed.
 func (c *Client) NotifyChange(ctx context.Context, filepath string) error {
-	uri := "file://" + filepath
-
 	content, err := os.ReadFile(filepath)
 	if err != nil {
 		if c.debug {
@@ -433,6 +680,15 @@ func (c *Client) NotifyChange(ctx context.Context, filepath string) error {
 		return nil // Don't error out if file disappeared
 	}
 
+	return c.NotifyContent(ctx, filepath, content)
+}
+
+// NotifyContent notifies the LSP server that the content of a file is now
+// content, without touching the file on disk. It is used to try out unsaved
+// edits; call NotifyChange afterwards to sync the server with the disk again.
+func (c *Client) NotifyContent(ctx context.Context, filepath string, content []byte) error {
+	uri := "file://" + filepath
+
 	c.openFilesMu.Lock()
 	fileInfo, isOpen := c.openFiles[uri]
 	if !isOpen {
@@ -521,9 +777,7 @@ func (c *Client) CloseFile(ctx context.Context, filepath string) error {
 	delete(c.openFiles, uri)
 
 	// Also clear diagnostics for the closed file
-	c.diagnosticsMu.Lock()
-	delete(c.diagnostics, protocol.DocumentUri(uri))
-	c.diagnosticsMu.Unlock()
+	c.ClearDiagnostics(protocol.DocumentUri(uri))
 
 	return nil
 }
@@ -537,6 +791,19 @@ func (c *Client) IsFileOpen(filepath string) bool {
 	return exists
 }
 
+// OpenFilePaths returns the paths of all files currently tracked as open, sorted.
+func (c *Client) OpenFilePaths() []string {
+	c.openFilesMu.RLock()
+	defer c.openFilesMu.RUnlock()
+
+	paths := make([]string, 0, len(c.openFiles))
+	for uri := range c.openFiles {
+		paths = append(paths, strings.TrimPrefix(uri, "file://"))
+	}
+	sort.Strings(paths)
+	return paths
+}
+
 // CloseAllFiles attempts to close all files currently tracked as open.
 func (c *Client) CloseAllFiles(ctx context.Context) {
 	c.openFilesMu.Lock()
@@ -580,6 +847,174 @@ func (c *Client) GetFileDiagnostics(uri protocol.DocumentUri) []protocol.Diagnos
 	return diagsCopy
 }
 
+// ClearDiagnostics drops the cached diagnostics of a file, e.g. because they
+// describe content that is no longer current.
+func (c *Client) ClearDiagnostics(uri protocol.DocumentUri) {
+	c.diagnosticsMu.Lock()
+	defer c.diagnosticsMu.Unlock()
+	delete(c.diagnostics, uri)
+	delete(c.diagnosticVersions, uri)
+}
+
+// GetAllDiagnostics returns a copy of the cached diagnostics of every file the
+// server has published diagnostics for, keyed by URI. Files whose diagnostics
+// have been cleared are omitted.
+func (c *Client) GetAllDiagnostics() map[protocol.DocumentUri][]protocol.Diagnostic {
+	c.diagnosticsMu.RLock()
+	defer c.diagnosticsMu.RUnlock()
+
+	all := make(map[protocol.DocumentUri][]protocol.Diagnostic, len(c.diagnostics))
+	for uri, diags := range c.diagnostics {
+		if len(diags) == 0 {
+			continue
+		}
+		diagsCopy := make([]protocol.Diagnostic, len(diags))
+		copy(diagsCopy, diags)
+		all[uri] = diagsCopy
+	}
+	return all
+}
+
+// SetLanguageDetector sets the detector that decides the language ID of opened
+// files. It must be called before any file is opened.
+func (c *Client) SetLanguageDetector(detector *LanguageDetector) {
+	c.languageDetector = detector
+}
+
+// languageID returns the language ID sent to the server when opening a file.
+func (c *Client) languageID(path string, content []byte) protocol.LanguageKind {
+	if c.languageDetector != nil {
+		if detection, ok := c.languageDetector.Detect(path, content); ok {
+			return detection.LanguageID
+		}
+	}
+	return DetectLanguageID(path)
+}
+
+// SetDiagnosticsTimeout sets how long WaitForDiagnostics waits for servers
+// without pull diagnostics to publish diagnostics.
+func (c *Client) SetDiagnosticsTimeout(timeout time.Duration) {
+	c.diagnosticsMu.Lock()
+	defer c.diagnosticsMu.Unlock()
+	c.diagnosticsTimeout = timeout
+}
+
+// WaitForDiagnostics returns the diagnostics of the version of a file the
+// server was last sent. Servers that support textDocument/diagnostic are asked
+// for them right away. For other servers it waits until they are published,
+// and returns the cached diagnostics if none arrive within the diagnostics
+// timeout.
+func (c *Client) WaitForDiagnostics(ctx context.Context, filepath string) ([]protocol.Diagnostic, error) {
+	uri := protocol.DocumentUri("file://" + filepath)
+
+	// Files that are not open have no version, any diagnostics will do
+	var version int32
+	c.openFilesMu.RLock()
+	if fileInfo, ok := c.openFiles[string(uri)]; ok {
+		version = fileInfo.Version
+	}
+	c.openFilesMu.RUnlock()
+
+	c.diagnosticsMu.Lock()
+	if published, ok := c.diagnosticVersions[uri]; ok && published >= version {
+		c.diagnosticsMu.Unlock()
+		return c.GetFileDiagnostics(uri), nil
+	}
+	if c.ServerCapabilities().DiagnosticProvider != nil {
+		c.diagnosticsMu.Unlock()
+		return c.pullDiagnostics(ctx, uri, version)
+	}
+	waiter := &diagnosticWaiter{version: version, done: make(chan struct{})}
+	c.diagnosticWaiters[uri] = append(c.diagnosticWaiters[uri], waiter)
+	timeout := c.diagnosticsTimeout
+	c.diagnosticsMu.Unlock()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-waiter.done:
+		return c.GetFileDiagnostics(uri), nil
+	case <-ctx.Done():
+		c.removeDiagnosticWaiter(uri, waiter)
+		return nil, ctx.Err()
+	case <-timer.C:
+		c.removeDiagnosticWaiter(uri, waiter)
+	}
+
+	if c.debug {
+		log.Printf("No diagnostics published for %s (Version %d) within %v", filepath, version, timeout)
+	}
+	return c.GetFileDiagnostics(uri), nil
+}
+
+// pullDiagnostics requests the diagnostics of a document with
+// textDocument/diagnostic and caches them. If the server does not support pull
+// diagnostics, the cached diagnostics are returned.
+func (c *Client) pullDiagnostics(ctx context.Context, uri protocol.DocumentUri, version int32) ([]protocol.Diagnostic, error) {
+	if c.ServerCapabilities().DiagnosticProvider == nil {
+		return c.GetFileDiagnostics(uri), nil
+	}
+
+	report, err := c.Diagnostic(ctx, protocol.DocumentDiagnosticParams{
+		TextDocument: protocol.TextDocumentIdentifier{URI: uri},
+	})
+	if err != nil {
+		log.Printf("failed to pull diagnostics for %s: %v", uri, err)
+		return c.GetFileDiagnostics(uri), nil
+	}
+
+	full, ok := report.Value.(protocol.RelatedFullDocumentDiagnosticReport)
+	if !ok {
+		// Unchanged since the last pull, the cache is up to date
+		return c.GetFileDiagnostics(uri), nil
+	}
+
+	c.storeDiagnostics(uri, version, full.Items)
+	return c.GetFileDiagnostics(uri), nil
+}
+
+// storeDiagnostics caches the diagnostics of a document version and wakes the
+// callers waiting for that version or an earlier one.
+func (c *Client) storeDiagnostics(uri protocol.DocumentUri, version int32, diagnostics []protocol.Diagnostic) {
+	c.diagnosticsMu.Lock()
+	defer c.diagnosticsMu.Unlock()
+
+	c.diagnostics[uri] = diagnostics
+	c.diagnosticVersions[uri] = version
+
+	var waiting []*diagnosticWaiter
+	for _, waiter := range c.diagnosticWaiters[uri] {
+		if waiter.version <= version {
+			close(waiter.done)
+		} else {
+			waiting = append(waiting, waiter)
+		}
+	}
+	if len(waiting) > 0 {
+		c.diagnosticWaiters[uri] = waiting
+	} else {
+		delete(c.diagnosticWaiters, uri)
+	}
+}
+
+// removeDiagnosticWaiter unregisters a waiter that gave up.
+func (c *Client) removeDiagnosticWaiter(uri protocol.DocumentUri, waiter *diagnosticWaiter) {
+	c.diagnosticsMu.Lock()
+	defer c.diagnosticsMu.Unlock()
+
+	waiters := c.diagnosticWaiters[uri]
+	for i, w := range waiters {
+		if w == waiter {
+			c.diagnosticWaiters[uri] = append(waiters[:i], waiters[i+1:]...)
+			break
+		}
+	}
+	if len(c.diagnosticWaiters[uri]) == 0 {
+		delete(c.diagnosticWaiters, uri)
+	}
+}
+
 // --- NEW LSP Request Methods ---
 // These methods use Call/Notify which are assumed to be defined in transport.go
 
@@ -0,0 +1,168 @@
+package lsp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestClient() *Client {
+	return &Client{
+		diagnostics:        make(map[protocol.DocumentUri][]protocol.Diagnostic),
+		diagnosticVersions: make(map[protocol.DocumentUri]int32),
+		diagnosticWaiters:  make(map[protocol.DocumentUri][]*diagnosticWaiter),
+		diagnosticsTimeout: DefaultDiagnosticsTimeout,
+		openFiles:          make(map[string]*OpenFileInfo),
+		handlers:           make(map[int32]chan *Message),
+		done:               make(chan struct{}),
+		readDone:           make(chan struct{}),
+	}
+}
+
+func TestWaitForDiagnosticsWakesOnVersion(t *testing.T) {
+	client := newTestClient()
+	uri := protocol.DocumentUri("file:///tmp/main.go")
+	client.openFiles[string(uri)] = &OpenFileInfo{Version: 2, URI: uri}
+
+	done := make(chan []protocol.Diagnostic)
+	go func() {
+		diags, err := client.WaitForDiagnostics(context.Background(), "/tmp/main.go")
+		assert.NoError(t, err)
+		done <- diags
+	}()
+
+	// Wait until the waiter is registered
+	require.Eventually(t, func() bool {
+		client.diagnosticsMu.RLock()
+		defer client.diagnosticsMu.RUnlock()
+		return len(client.diagnosticWaiters[uri]) == 1
+	}, time.Second, time.Millisecond)
+
+	// Diagnostics of an older version do not wake the waiter
+	client.storeDiagnostics(uri, 1, []protocol.Diagnostic{{Message: "stale"}})
+	select {
+	case <-done:
+		t.Fatal("woken by diagnostics of an older version")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	client.storeDiagnostics(uri, 2, []protocol.Diagnostic{{Message: "fresh"}})
+	select {
+	case diags := <-done:
+		require.Len(t, diags, 1)
+		assert.Equal(t, "fresh", diags[0].Message)
+	case <-time.After(time.Second):
+		t.Fatal("not woken by diagnostics of the current version")
+	}
+
+	assert.Empty(t, client.diagnosticWaiters)
+}
+
+func TestWaitForDiagnosticsUsesCurrentCache(t *testing.T) {
+	client := newTestClient()
+	uri := protocol.DocumentUri("file:///tmp/main.go")
+	client.openFiles[string(uri)] = &OpenFileInfo{Version: 1, URI: uri}
+	client.storeDiagnostics(uri, 1, []protocol.Diagnostic{{Message: "cached"}})
+
+	diags, err := client.WaitForDiagnostics(context.Background(), "/tmp/main.go")
+	require.NoError(t, err)
+	require.Len(t, diags, 1)
+	assert.Equal(t, "cached", diags[0].Message)
+}
+
+func TestClearDiagnostics(t *testing.T) {
+	client := newTestClient()
+	uri := protocol.DocumentUri("file:///tmp/main.go")
+	client.storeDiagnostics(uri, 2, []protocol.Diagnostic{{Message: "stale"}})
+
+	client.ClearDiagnostics(uri)
+	assert.Nil(t, client.GetFileDiagnostics(uri))
+	assert.Empty(t, client.GetAllDiagnostics())
+	assert.NotContains(t, client.diagnosticVersions, uri)
+}
+
+func TestWaitForDiagnosticsTimeout(t *testing.T) {
+	client := newTestClient()
+	client.SetDiagnosticsTimeout(10 * time.Millisecond)
+	uri := protocol.DocumentUri("file:///tmp/main.go")
+	client.openFiles[string(uri)] = &OpenFileInfo{Version: 3, URI: uri}
+	client.storeDiagnostics(uri, 2, []protocol.Diagnostic{{Message: "stale"}})
+
+	// Without pull diagnostics support the stale cache is returned after the timeout
+	diags, err := client.WaitForDiagnostics(context.Background(), "/tmp/main.go")
+	require.NoError(t, err)
+	require.Len(t, diags, 1)
+	assert.Equal(t, "stale", diags[0].Message)
+	assert.Empty(t, client.diagnosticWaiters)
+}
+
+func TestWaitForDiagnosticsPullsAtOnce(t *testing.T) {
+	serverIn, clientOut := io.Pipe()
+	clientIn, serverOut := io.Pipe()
+
+	client := newTestClient()
+	client.stdin = clientOut
+	client.stdout = bufio.NewReader(clientIn)
+	client.capabilities.DiagnosticProvider = &protocol.Or_ServerCapabilities_diagnosticProvider{}
+	go client.handleMessages()
+
+	// The server answers the pull without ever publishing diagnostics
+	go func() {
+		request, err := ReadMessage(bufio.NewReader(serverIn))
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, "textDocument/diagnostic", request.Method)
+		result := `{"kind":"full","items":[{"range":{"start":{"line":0,"character":0},"end":{"line":0,"character":1}},"message":"pulled"}]}`
+		assert.NoError(t, WriteMessage(serverOut, &Message{JSONRPC: "2.0", ID: request.ID, Result: json.RawMessage(result)}))
+	}()
+
+	uri := protocol.DocumentUri("file:///tmp/main.go")
+	client.openFiles[string(uri)] = &OpenFileInfo{Version: 1, URI: uri}
+
+	start := time.Now()
+	diags, err := client.WaitForDiagnostics(context.Background(), "/tmp/main.go")
+	require.NoError(t, err)
+	require.Len(t, diags, 1)
+	assert.Equal(t, "pulled", diags[0].Message)
+	assert.Less(t, time.Since(start), DefaultDiagnosticsTimeout, "pulled only after the push timeout")
+	assert.Empty(t, client.diagnosticWaiters)
+}
+
+func TestHandleDiagnosticsUnversioned(t *testing.T) {
+	tests := []struct {
+		name           string
+		versionSupport bool
+		want           int32
+	}{
+		// Without version support the publish describes the open version
+		{name: "without version support", versionSupport: false, want: 2},
+		// The server was asked for versions, so the publish may be stale
+		{name: "with version support", versionSupport: true, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient()
+			client.diagnosticVersionSupport = tt.versionSupport
+			uri := protocol.DocumentUri("file:///tmp/main.go")
+			client.openFiles[string(uri)] = &OpenFileInfo{Version: 2, URI: uri}
+
+			params, err := json.Marshal(protocol.PublishDiagnosticsParams{
+				URI:         uri,
+				Diagnostics: []protocol.Diagnostic{{Message: "unversioned"}},
+			})
+			require.NoError(t, err)
+			HandleDiagnostics(client, params)
+
+			assert.Equal(t, tt.want, client.diagnosticVersions[uri])
+			assert.Len(t, client.GetFileDiagnostics(uri), 1)
+		})
+	}
+}
@@ -8,6 +8,13 @@ import (
 )
 
 func DetectLanguageID(uri string) protocol.LanguageKind {
+	switch name := strings.ToLower(filepath.Base(uri)); {
+	case name == "dockerfile" || strings.HasPrefix(name, "dockerfile."):
+		return protocol.LangDockerfile
+	case name == "makefile" || name == "gnumakefile":
+		return protocol.LangMakefile
+	}
+
 	ext := strings.ToLower(filepath.Ext(uri))
 	switch ext {
 	case ".abap":
@@ -74,7 +81,7 @@ func DetectLanguageID(uri string) protocol.LanguageKind {
 		return protocol.LangLess
 	case ".lua":
 		return protocol.LangLua
-	case ".makefile", "makefile":
+	case ".makefile", ".mk":
 		return protocol.LangMakefile
 	case ".md", ".markdown":
 		return protocol.LangMarkdown
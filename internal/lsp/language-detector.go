@@ -0,0 +1,299 @@
+package lsp
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/isaacphi/mcp-language-server/internal/utilities"
+)
+
+// LanguageRule describes the files that belong to a configured language.
+type LanguageRule struct {
+	// Language is the name of the configured language files are routed to
+	Language string
+	// LanguageID is sent in didOpen, detected from the file name if empty
+	LanguageID protocol.LanguageKind
+	// Extensions such as ".ts", including the dot
+	Extensions []string
+	// Filenames are exact base names such as "Dockerfile"
+	Filenames []string
+	// Patterns are globs matched against the base name, or against the
+	// workspace-relative path if they contain a slash; ** matches any number
+	// of directories
+	Patterns []string
+	// Shebangs are interpreter names such as "python3" or "node"
+	Shebangs []string
+	// Modelines are names used in vim and emacs modelines, in addition to
+	// Language and LanguageID
+	Modelines []string
+}
+
+// Detection is the language a file was detected to be in.
+type Detection struct {
+	Language   string
+	LanguageID protocol.LanguageKind
+}
+
+// LanguageDetector decides which configured language a file belongs to. A
+// modeline in the file wins, then a shebang, then the file name, a pattern and
+// finally the extension. Rules are tried in order for each of these, except
+// that for extensions the last rule wins, as the extension map always did.
+type LanguageDetector struct {
+	rules        []LanguageRule
+	workspaceDir string
+	extensions   map[string]int
+}
+
+// modelineLines is how many lines at the start and end of a file are searched
+// for a modeline, as vim does by default.
+const modelineLines = 5
+
+// contentPeekSize is how many bytes are read from each end of a file to find
+// its shebang and modelines.
+const contentPeekSize = 4096
+
+var (
+	vimModeline   = regexp.MustCompile(`(?:^|\s)(?:vi|vim|ex):.*?\b(?:ft|filetype|syntax)=([\w+#.-]+)`)
+	emacsModeline = regexp.MustCompile(`-\*-(.*?)-\*-`)
+)
+
+// NewLanguageDetector creates a detector for the given rules. Patterns that
+// contain a slash are matched relative to workspaceDir.
+func NewLanguageDetector(workspaceDir string, rules []LanguageRule) *LanguageDetector {
+	d := &LanguageDetector{
+		rules:        rules,
+		workspaceDir: workspaceDir,
+		extensions:   make(map[string]int),
+	}
+	for i, rule := range rules {
+		for _, ext := range rule.Extensions {
+			if j, exists := d.extensions[ext]; exists && rules[j].Language != rule.Language {
+				log.Printf("Warning: Extension %s is associated with multiple languages. Using %s.", ext, rule.Language)
+			}
+			d.extensions[ext] = i
+		}
+	}
+	return d
+}
+
+// Detect returns the language of the file at path. content is the content of
+// the file; if it is nil the start and end of the file are read from disk,
+// and only the name is used if the file cannot be read.
+func (d *LanguageDetector) Detect(path string, content []byte) (Detection, bool) {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	if content == nil {
+		content = peekFile(path)
+	}
+	head, tail := contentLines(content)
+
+	if names := modelineNames(append(head, tail...)); len(names) > 0 {
+		if i, ok := d.find(func(rule LanguageRule) bool { return matchesModeline(rule, names) }); ok {
+			return d.detection(i, path), true
+		}
+	}
+	if len(head) > 0 {
+		if interpreter := shebangInterpreter(head[0]); interpreter != "" {
+			if i, ok := d.find(func(rule LanguageRule) bool { return matchesShebang(rule, interpreter) }); ok {
+				return d.detection(i, path), true
+			}
+		}
+	}
+
+	base := filepath.Base(path)
+	if i, ok := d.find(func(rule LanguageRule) bool { return containsString(rule.Filenames, base) }); ok {
+		return d.detection(i, path), true
+	}
+	rel := filepath.ToSlash(path)
+	if d.workspaceDir != "" {
+		if r, err := filepath.Rel(d.workspaceDir, path); err == nil && !strings.HasPrefix(r, "..") {
+			rel = filepath.ToSlash(r)
+		}
+	}
+	if i, ok := d.find(func(rule LanguageRule) bool { return matchesPattern(rule, base, rel) }); ok {
+		return d.detection(i, path), true
+	}
+	if i, ok := d.extensions[filepath.Ext(path)]; ok {
+		return d.detection(i, path), true
+	}
+	return Detection{}, false
+}
+
+// find returns the index of the first rule that matches.
+func (d *LanguageDetector) find(match func(LanguageRule) bool) (int, bool) {
+	for i, rule := range d.rules {
+		if match(rule) {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// detection returns the language of rule i for the file at path. Without a
+// configured language ID it is detected from the file name, falling back to
+// the name of the language.
+func (d *LanguageDetector) detection(i int, path string) Detection {
+	rule := d.rules[i]
+	languageID := rule.LanguageID
+	if languageID == "" {
+		languageID = DetectLanguageID(path)
+	}
+	if languageID == "" {
+		languageID = protocol.LanguageKind(rule.Language)
+	}
+	return Detection{Language: rule.Language, LanguageID: languageID}
+}
+
+func matchesModeline(rule LanguageRule, names []string) bool {
+	for _, name := range names {
+		if strings.EqualFold(name, rule.Language) || strings.EqualFold(name, string(rule.LanguageID)) {
+			return true
+		}
+		for _, modeline := range rule.Modelines {
+			if strings.EqualFold(name, modeline) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// matchesShebang reports whether interpreter is one of the rule's shebangs,
+// ignoring a version suffix such as the 3.12 of python3.12.
+func matchesShebang(rule LanguageRule, interpreter string) bool {
+	for _, shebang := range rule.Shebangs {
+		if version, ok := strings.CutPrefix(interpreter, shebang); ok && strings.Trim(version, "0123456789.") == "" {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesPattern(rule LanguageRule, base, rel string) bool {
+	for _, pattern := range rule.Patterns {
+		name := base
+		if strings.Contains(pattern, "/") {
+			name = rel
+		}
+		if utilities.MatchGlob(pattern, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// shebangInterpreter returns the name of the interpreter of a shebang line,
+// looking through /usr/bin/env and its options, or "" if line is not one.
+func shebangInterpreter(line string) string {
+	if !strings.HasPrefix(line, "#!") {
+		return ""
+	}
+	fields := strings.Fields(strings.TrimPrefix(line, "#!"))
+	if len(fields) == 0 {
+		return ""
+	}
+	interpreter := filepath.Base(fields[0])
+	if interpreter != "env" {
+		return interpreter
+	}
+	for _, field := range fields[1:] {
+		if strings.HasPrefix(field, "-") || strings.Contains(field, "=") {
+			continue
+		}
+		return filepath.Base(field)
+	}
+	return ""
+}
+
+// modelineNames returns the file types named by vim and emacs modelines in
+// lines, such as python in "vim: set ft=python:" or "-*- mode: python -*-".
+func modelineNames(lines []string) []string {
+	var names []string
+	for _, line := range lines {
+		if m := vimModeline.FindStringSubmatch(line); m != nil {
+			names = append(names, m[1])
+		}
+		m := emacsModeline.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		for _, part := range strings.Split(m[1], ";") {
+			key, value, found := strings.Cut(part, ":")
+			switch {
+			case !found && strings.TrimSpace(key) != "":
+				names = append(names, strings.TrimSpace(key))
+			case found && strings.EqualFold(strings.TrimSpace(key), "mode"):
+				names = append(names, strings.TrimSpace(value))
+			}
+		}
+	}
+	return names
+}
+
+// contentLines returns the first and last lines of content in which shebangs
+// and modelines are looked for.
+func contentLines(content []byte) (head, tail []string) {
+	if len(content) == 0 {
+		return nil, nil
+	}
+	lines := strings.Split(strings.ReplaceAll(string(content), "\r\n", "\n"), "\n")
+	if len(lines) <= 2*modelineLines {
+		return lines, nil
+	}
+	return lines[:modelineLines], lines[len(lines)-modelineLines:]
+}
+
+// peekFile returns the start and end of a file, separated by a newline if the
+// file is larger than twice contentPeekSize, or nil if it cannot be read.
+func peekFile(path string) []byte {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil || !info.Mode().IsRegular() {
+		return nil
+	}
+	if info.Size() <= 2*contentPeekSize {
+		content, err := io.ReadAll(f)
+		if err != nil {
+			return nil
+		}
+		return content
+	}
+
+	head := make([]byte, contentPeekSize)
+	if _, err := io.ReadFull(f, head); err != nil {
+		return nil
+	}
+	tail := make([]byte, contentPeekSize)
+	if _, err := f.ReadAt(tail, info.Size()-contentPeekSize); err != nil {
+		return nil
+	}
+	// Drop the partial lines at the cut so that they are not mistaken for modelines
+	if i := bytes.LastIndexByte(head, '\n'); i >= 0 {
+		head = head[:i]
+	}
+	if i := bytes.IndexByte(tail, '\n'); i >= 0 {
+		tail = tail[i+1:]
+	}
+	return append(append(head, '\n'), tail...)
+}
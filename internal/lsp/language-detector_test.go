@@ -0,0 +1,69 @@
+package lsp
+
+import (
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestDetector() *LanguageDetector {
+	return NewLanguageDetector("/ws", []LanguageRule{
+		{Language: "c", Extensions: []string{".c", ".h"}},
+		{Language: "cpp", LanguageID: protocol.LangCPP, Extensions: []string{".cpp"}, Patterns: []string{"src/engine/**/*.h"}},
+		{Language: "python", Extensions: []string{".py"}, Shebangs: []string{"python"}},
+		{Language: "shell", LanguageID: protocol.LangShellScript, Shebangs: []string{"sh", "bash"}, Modelines: []string{"sh"}},
+		{Language: "docker", Filenames: []string{"Dockerfile"}, Patterns: []string{"Dockerfile.*"}},
+	})
+}
+
+func TestLanguageDetectorDetect(t *testing.T) {
+	d := newTestDetector()
+
+	tests := []struct {
+		name       string
+		path       string
+		content    string
+		language   string
+		languageID protocol.LanguageKind
+	}{
+		{"extension", "/ws/main.c", "int main() {}\n", "c", protocol.LangC},
+		{"configured language ID", "/ws/main.cpp", "", "cpp", protocol.LangCPP},
+		{"pattern beats extension", "/ws/src/engine/render/mesh.h", "", "cpp", protocol.LangCPP},
+		{"extension outside pattern", "/ws/include/mesh.h", "", "c", protocol.LangC},
+		{"file name", "/ws/Dockerfile", "FROM golang\n", "docker", protocol.LangDockerfile},
+		{"file name pattern", "/ws/Dockerfile.dev", "", "docker", protocol.LangDockerfile},
+		{"env shebang with version", "/ws/bin/tool", "#!/usr/bin/env python3.12\nprint()\n", "python", protocol.LanguageKind("python")},
+		{"env shebang with options", "/ws/bin/run", "#!/usr/bin/env -S bash -e\necho\n", "shell", protocol.LangShellScript},
+		{"shebang path", "/ws/bin/setup", "#!/bin/sh\necho\n", "shell", protocol.LangShellScript},
+		{"vim modeline beats extension", "/ws/notes.py", "echo\n# vim: set ft=sh:\n", "shell", protocol.LangShellScript},
+		{"emacs modeline", "/ws/build", "# -*- mode: python; coding: utf-8 -*-\n", "python", protocol.LanguageKind("python")},
+		{"emacs short modeline", "/ws/build", "# -*- c -*-\n", "c", protocol.LanguageKind("c")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			detection, ok := d.Detect(tt.path, []byte(tt.content))
+			assert.True(t, ok)
+			assert.Equal(t, tt.language, detection.Language)
+			assert.Equal(t, tt.languageID, detection.LanguageID)
+		})
+	}
+
+	_, ok := d.Detect("/ws/README", []byte("#!/usr/bin/env ruby\n"))
+	assert.False(t, ok)
+}
+
+func TestShebangInterpreter(t *testing.T) {
+	assert.Equal(t, "node", shebangInterpreter("#!/usr/bin/env node"))
+	assert.Equal(t, "python3", shebangInterpreter("#! /usr/local/bin/python3 -u"))
+	assert.Equal(t, "node", shebangInterpreter("#!/usr/bin/env NODE_ENV=test node"))
+	assert.Equal(t, "", shebangInterpreter("# not a shebang"))
+	assert.Equal(t, "", shebangInterpreter("#!/usr/bin/env"))
+}
+
+func TestModelineNames(t *testing.T) {
+	assert.Equal(t, []string{"python"}, modelineNames([]string{"# vim: ft=python"}))
+	assert.Equal(t, []string{"cpp"}, modelineNames([]string{"// vim: set filetype=cpp ts=4 :"}))
+	assert.Equal(t, []string{"ruby"}, modelineNames([]string{"# -*- mode: ruby -*-"}))
+	assert.Nil(t, modelineNames([]string{"x := map[string]int{}", "// -*- coding: utf-8 -*-"}))
+}
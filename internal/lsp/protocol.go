@@ -2,6 +2,7 @@ package lsp
 
 import (
 	"encoding/json"
+	"fmt"
 )
 
 // Message represents a JSON-RPC 2.0 message
@@ -20,6 +21,10 @@ type ResponseError struct {
 	Message string `json:"message"`
 }
 
+func (e *ResponseError) Error() string {
+	return fmt.Sprintf("%s (code: %d)", e.Message, e.Code)
+}
+
 func NewRequest(id int32, method string, params interface{}) (*Message, error) {
 	paramsJSON, err := json.Marshal(params)
 	if err != nil {
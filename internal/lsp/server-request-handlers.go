@@ -99,10 +99,20 @@ func HandleDiagnostics(client *Client, params json.RawMessage) {
 		return
 	}
 
-	client.diagnosticsMu.Lock()
-	defer client.diagnosticsMu.Unlock()
+	// Servers that were not asked to report versions are assumed to describe
+	// the latest version of the file sent to them. If versions were asked for,
+	// an unversioned publish may be for an older version and is not taken as
+	// current
+	version := diagParams.Version
+	if version == 0 && !client.supportsDiagnosticVersions() {
+		client.openFilesMu.RLock()
+		if fileInfo, ok := client.openFiles[string(diagParams.URI)]; ok {
+			version = fileInfo.Version
+		}
+		client.openFilesMu.RUnlock()
+	}
 
-	client.diagnostics[diagParams.URI] = diagParams.Diagnostics
+	client.storeDiagnostics(diagParams.URI, version, diagParams.Diagnostics)
 
 	log.Printf("Received diagnostics for %s: %d items", diagParams.URI, len(diagParams.Diagnostics))
 }
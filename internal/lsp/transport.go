@@ -4,11 +4,14 @@ import (
 	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"os"
 	"strings"
+	"syscall"
+	"time"
 )
 
 var debug = os.Getenv("DEBUG") != ""
@@ -97,6 +100,21 @@ func (c *Client) handleMessages() {
 			if debug {
 				log.Printf("Error reading message: %v", err)
 			}
+			close(c.readDone)
+			// A process closes its output when it exits, which the goroutine
+			// waiting for it reports with the exit status
+			if c.exited != nil {
+				select {
+				case <-c.exited:
+					return
+				case <-time.After(exitOutputTimeout):
+				}
+			}
+			// The server closed its output, so no more responses will arrive
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				err = errors.New("connection closed")
+			}
+			c.disconnect(err)
 			return
 		}
 
@@ -142,7 +160,7 @@ func (c *Client) handleMessages() {
 			}
 
 			// Send response back to server
-			if err := WriteMessage(c.stdin, response); err != nil {
+			if err := c.write(response); err != nil {
 				log.Printf("Error sending response to server: %v", err)
 			}
 
@@ -211,7 +229,7 @@ func (c *Client) Call(ctx context.Context, method string, params interface{}, re
 	}()
 
 	// Send request
-	if err := WriteMessage(c.stdin, msg); err != nil {
+	if err := c.write(msg); err != nil {
 		return fmt.Errorf("failed to send request: %w", err)
 	}
 
@@ -219,15 +237,22 @@ func (c *Client) Call(ctx context.Context, method string, params interface{}, re
 		log.Printf("Waiting for response to request ID: %d", id)
 	}
 
-	// Wait for response
-	resp := <-ch
+	// Wait for response, unless the server goes away first
+	var resp *Message
+	select {
+	case resp = <-ch:
+	case <-c.done:
+		return c.connErr
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 
 	if debug {
 		log.Printf("Received response for request ID: %d", id)
 	}
 
 	if resp.Error != nil {
-		return fmt.Errorf("request failed: %s (code: %d)", resp.Error.Message, resp.Error.Code)
+		return fmt.Errorf("request failed: %w", resp.Error)
 	}
 
 	if result != nil {
@@ -256,12 +281,31 @@ func (c *Client) Notify(ctx context.Context, method string, params interface{})
 		return fmt.Errorf("failed to create notification: %w", err)
 	}
 
-	if err := WriteMessage(c.stdin, msg); err != nil {
+	if err := c.write(msg); err != nil {
 		return fmt.Errorf("failed to send notification: %w", err)
 	}
 
 	return nil
 }
 
+// write sends a message to the server. Nothing is sent once the connection is
+// lost, and a broken pipe marks the connection as lost.
+func (c *Client) write(msg *Message) error {
+	c.writeMu.Lock()
+	select {
+	case <-c.done:
+		c.writeMu.Unlock()
+		return c.connErr
+	default:
+	}
+	err := WriteMessage(c.stdin, msg)
+	c.writeMu.Unlock()
+	if errors.Is(err, syscall.EPIPE) || errors.Is(err, os.ErrClosed) {
+		c.disconnect(err)
+		return c.connErr
+	}
+	return err
+}
+
 type NotificationHandler func(params json.RawMessage)
 type ServerRequestHandler func(params json.RawMessage) (interface{}, error)
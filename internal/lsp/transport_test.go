@@ -0,0 +1,108 @@
+package lsp
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCallFailsWhenServerExits(t *testing.T) {
+	serverIn, clientOut := io.Pipe()
+	clientIn, serverOut := io.Pipe()
+
+	c := newTestClient()
+	c.stdin = clientOut
+	c.stdout = bufio.NewReader(clientIn)
+	go c.handleMessages()
+
+	// The server reads the request and dies without answering it
+	go func() {
+		_, err := ReadMessage(bufio.NewReader(serverIn))
+		assert.NoError(t, err)
+		serverOut.Close()
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := c.Call(ctx, "workspace/symbol", nil, nil)
+	require.ErrorIs(t, err, ErrServerExited)
+	assert.ErrorIs(t, c.Err(), ErrServerExited)
+
+	select {
+	case <-c.Done():
+	default:
+		t.Fatal("Done is not closed after the server exited")
+	}
+
+	// Later requests fail at once instead of blocking
+	err = c.Call(ctx, "workspace/symbol", nil, nil)
+	assert.ErrorIs(t, err, ErrServerExited)
+	assert.ErrorIs(t, c.Notify(ctx, "textDocument/didOpen", nil), ErrServerExited)
+}
+
+func TestCallRespectsContext(t *testing.T) {
+	serverIn, clientOut := io.Pipe()
+	clientIn, _ := io.Pipe()
+
+	c := newTestClient()
+	c.stdin = clientOut
+	c.stdout = bufio.NewReader(clientIn)
+	go c.handleMessages()
+	go io.Copy(io.Discard, serverIn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err := c.Call(ctx, "workspace/symbol", nil, nil)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.NoError(t, c.Err())
+}
+
+func TestDoneWhenProcessExitsWithStdoutOpen(t *testing.T) {
+	// The shell exits while the background sleep keeps its stdout open
+	c, err := NewClient("sh", "-c", "sleep 2 & exit 3")
+	require.NoError(t, err)
+
+	select {
+	case <-c.Done():
+	case <-time.After(time.Second):
+		t.Fatal("Done is not closed after the process exited")
+	}
+	assert.ErrorIs(t, c.Err(), ErrServerExited)
+	assert.ErrorContains(t, c.Err(), "exit status 3")
+
+	start := time.Now()
+	assert.NoError(t, c.Close())
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestResponseDeliveredWhenProcessExitsAfterWriting(t *testing.T) {
+	// The server answers the first request and exits right away
+	body := `{"jsonrpc":"2.0","id":1,"result":42}`
+	script := fmt.Sprintf(`head -c 1 >/dev/null; printf 'Content-Length: %d\r\n\r\n%s'`, len(body), body)
+	c, err := NewClient("sh", "-c", script)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var result int
+	require.NoError(t, c.Call(ctx, "workspace/symbol", nil, &result))
+	assert.Equal(t, 42, result)
+
+	select {
+	case <-c.Done():
+	case <-time.After(time.Second):
+		t.Fatal("Done is not closed after the process exited")
+	}
+	assert.ErrorIs(t, c.Err(), ErrServerExited)
+	assert.ErrorContains(t, c.Err(), "process exited")
+	assert.NoError(t, c.Close())
+}
@@ -1,6 +1,9 @@
 package protocol
 
-import "fmt"
+import (
+	"encoding/json"
+	"fmt"
+)
 
 // TextEditResult is an interface for types that represent workspace symbols
 type WorkspaceSymbolResult interface {
@@ -115,3 +118,91 @@ func (e Or_TextDocumentEdit_edits_Elem) AsTextEdit() (TextEdit, error) {
 		return TextEdit{}, fmt.Errorf("unknown text edit type: %T", e.Value)
 	}
 }
+
+// definitionLocations flattens the Definition | []DefinitionLink union shared by
+// the definition-like requests into plain Locations. LocationLinks are converted
+// using their target selection range, which points at the symbol's name.
+func definitionLocations(value interface{}) ([]Location, error) {
+	switch v := value.(type) {
+	case nil:
+		return []Location{}, nil
+	case Definition:
+		switch d := v.Value.(type) {
+		case nil:
+			return []Location{}, nil
+		case Location:
+			return []Location{d}, nil
+		case []Location:
+			return d, nil
+		default:
+			return nil, fmt.Errorf("unknown definition type: %T", d)
+		}
+	case []DefinitionLink:
+		locations := make([]Location, len(v))
+		for i, link := range v {
+			locations[i] = Location{
+				URI:   link.TargetURI,
+				Range: link.TargetSelectionRange,
+			}
+		}
+		return locations, nil
+	default:
+		return nil, fmt.Errorf("unknown definition result type: %T", value)
+	}
+}
+
+// Locations converts the Value to a slice of Location
+func (r Or_Result_textDocument_definition) Locations() ([]Location, error) {
+	return definitionLocations(r.Value)
+}
+
+// Locations converts the Value to a slice of Location
+func (r Or_Result_textDocument_implementation) Locations() ([]Location, error) {
+	return definitionLocations(r.Value)
+}
+
+// Locations converts the Value to a slice of Location
+func (r Or_Result_textDocument_typeDefinition) Locations() ([]Location, error) {
+	return definitionLocations(r.Value)
+}
+
+// Items converts the Value to a slice of CompletionItem, reporting whether the
+// list is incomplete
+func (r Or_Result_textDocument_completion) Items() ([]CompletionItem, bool, error) {
+	switch v := r.Value.(type) {
+	case nil:
+		return nil, false, nil
+	case CompletionList:
+		return v.Items, v.IsIncomplete, nil
+	case []CompletionItem:
+		return v, false, nil
+	default:
+		return nil, false, fmt.Errorf("unknown completion result type: %T", r.Value)
+	}
+}
+
+// UnmarshalJSON decodes an InlayHint whose label may be sent either as a plain
+// string or as label parts. A string label becomes a single label part.
+func (h *InlayHint) UnmarshalJSON(data []byte) error {
+	type inlayHint InlayHint
+	var raw struct {
+		inlayHint
+		Label json.RawMessage `json:"label"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*h = InlayHint(raw.inlayHint)
+
+	var label string
+	if err := json.Unmarshal(raw.Label, &label); err == nil {
+		h.Label = []InlayHintLabelPart{{Value: label}}
+		return nil
+	}
+	var parts []InlayHintLabelPart
+	if err := json.Unmarshal(raw.Label, &parts); err != nil {
+		return fmt.Errorf("invalid inlay hint label: %w", err)
+	}
+	h.Label = parts
+	return nil
+}
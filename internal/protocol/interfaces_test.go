@@ -0,0 +1,74 @@
+package protocol
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImplementationLocations(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		expected []Location
+	}{
+		{
+			name:     "null",
+			response: `null`,
+			expected: []Location{},
+		},
+		{
+			name:     "single location",
+			response: `{"uri":"file:///a.go","range":{"start":{"line":1,"character":2},"end":{"line":1,"character":5}}}`,
+			expected: []Location{
+				{URI: "file:///a.go", Range: Range{Start: Position{Line: 1, Character: 2}, End: Position{Line: 1, Character: 5}}},
+			},
+		},
+		{
+			name:     "location array",
+			response: `[{"uri":"file:///a.go","range":{"start":{"line":1,"character":0},"end":{"line":1,"character":3}}},{"uri":"file:///b.go","range":{"start":{"line":4,"character":0},"end":{"line":4,"character":3}}}]`,
+			expected: []Location{
+				{URI: "file:///a.go", Range: Range{Start: Position{Line: 1}, End: Position{Line: 1, Character: 3}}},
+				{URI: "file:///b.go", Range: Range{Start: Position{Line: 4}, End: Position{Line: 4, Character: 3}}},
+			},
+		},
+		{
+			name:     "location links",
+			response: `[{"targetUri":"file:///c.ts","targetRange":{"start":{"line":10,"character":0},"end":{"line":20,"character":1}},"targetSelectionRange":{"start":{"line":10,"character":6},"end":{"line":10,"character":9}}}]`,
+			expected: []Location{
+				{URI: "file:///c.ts", Range: Range{Start: Position{Line: 10, Character: 6}, End: Position{Line: 10, Character: 9}}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var result Or_Result_textDocument_implementation
+			require.NoError(t, json.Unmarshal([]byte(tt.response), &result))
+
+			locations, err := result.Locations()
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, locations)
+		})
+	}
+}
+
+func TestInlayHintUnmarshalLabel(t *testing.T) {
+	var hints []InlayHint
+	response := `[
+		{"position": {"line": 1, "character": 4}, "label": ": number", "kind": 1, "paddingLeft": true},
+		{"position": {"line": 2, "character": 8}, "label": [{"value": "a:"}, {"value": " "}], "kind": 2}
+	]`
+	require.NoError(t, json.Unmarshal([]byte(response), &hints))
+	require.Len(t, hints, 2)
+
+	assert.Equal(t, []InlayHintLabelPart{{Value: ": number"}}, hints[0].Label)
+	assert.Equal(t, Type, hints[0].Kind)
+	assert.True(t, hints[0].PaddingLeft)
+	assert.Equal(t, Position{Line: 1, Character: 4}, hints[0].Position)
+
+	assert.Equal(t, []InlayHintLabelPart{{Value: "a:"}, {Value: " "}}, hints[1].Label)
+	assert.Equal(t, Parameter, hints[1].Kind)
+}
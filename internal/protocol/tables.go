@@ -28,3 +28,31 @@ var TableKindMap = map[SymbolKind]string{
 	Operator:      "Operator",
 	TypeParameter: "TypeParameter",
 }
+
+var TableCompletionItemKindMap = map[CompletionItemKind]string{
+	TextCompletion:          "Text",
+	MethodCompletion:        "Method",
+	FunctionCompletion:      "Function",
+	ConstructorCompletion:   "Constructor",
+	FieldCompletion:         "Field",
+	VariableCompletion:      "Variable",
+	ClassCompletion:         "Class",
+	InterfaceCompletion:     "Interface",
+	ModuleCompletion:        "Module",
+	PropertyCompletion:      "Property",
+	UnitCompletion:          "Unit",
+	ValueCompletion:         "Value",
+	EnumCompletion:          "Enum",
+	KeywordCompletion:       "Keyword",
+	SnippetCompletion:       "Snippet",
+	ColorCompletion:         "Color",
+	FileCompletion:          "File",
+	ReferenceCompletion:     "Reference",
+	FolderCompletion:        "Folder",
+	EnumMemberCompletion:    "EnumMember",
+	ConstantCompletion:      "Constant",
+	StructCompletion:        "Struct",
+	EventCompletion:         "Event",
+	OperatorCompletion:      "Operator",
+	TypeParameterCompletion: "TypeParameter",
+}
@@ -0,0 +1,209 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// DiagnosticsChange describes how an edit changed the diagnostics of the
+// workspace.
+type DiagnosticsChange struct {
+	// Current holds the diagnostics of the edited file after the edit
+	Current []Diagnostic `json:"current"`
+	// Introduced and Resolved may include other files, e.g. callers broken by the edit
+	Introduced []Diagnostic `json:"introduced"`
+	Resolved   []Diagnostic `json:"resolved"`
+}
+
+func (c *DiagnosticsChange) text(filePath string) string {
+	var output strings.Builder
+	if len(c.Current) == 0 {
+		output.WriteString(fmt.Sprintf("Diagnostics in %s after the edit: none\n", filePath))
+	} else {
+		output.WriteString(fmt.Sprintf("Diagnostics in %s after the edit: %s\n", filePath, formatSeverityCounts(severityCounts(c.Current))))
+	}
+
+	if len(c.Introduced) == 0 && len(c.Resolved) == 0 {
+		output.WriteString("No diagnostics were introduced or resolved by the edit.\n")
+		return output.String()
+	}
+
+	// Both lists are grouped by file, the edited file first
+	var paths []string
+	byPath := make(map[string][2][]Diagnostic)
+	for i, diags := range [][]Diagnostic{c.Introduced, c.Resolved} {
+		for _, diag := range diags {
+			group, ok := byPath[diag.Location.Path]
+			if !ok {
+				paths = append(paths, diag.Location.Path)
+			}
+			group[i] = append(group[i], diag)
+			byPath[diag.Location.Path] = group
+		}
+	}
+	sort.Slice(paths, func(i, j int) bool {
+		if (paths[i] == filePath) != (paths[j] == filePath) {
+			return paths[i] == filePath
+		}
+		return paths[i] < paths[j]
+	})
+
+	for _, path := range paths {
+		group := byPath[path]
+		output.WriteString(strings.Repeat("=", 80) + "\n")
+		output.WriteString(path + "\n")
+		if len(group[0]) > 0 {
+			output.WriteString(fmt.Sprintf("Introduced (%d):\n", len(group[0])))
+			for _, diag := range group[0] {
+				output.WriteString("  " + formatDiagnosticLine(diag) + "\n")
+			}
+		}
+		if len(group[1]) > 0 {
+			output.WriteString(fmt.Sprintf("Resolved (%d):\n", len(group[1])))
+			for _, diag := range group[1] {
+				output.WriteString("  was " + formatDiagnosticLine(diag) + "\n")
+			}
+		}
+	}
+	return output.String()
+}
+
+// ApplyTextEditsWithDiagnostics applies text edits like ApplyTextEdits, then
+// notifies the server of the change, waits for fresh diagnostics and reports
+// the diagnostics the edit introduced and resolved, in the edited file and in
+// any other file the server published diagnostics for.
+func ApplyTextEditsWithDiagnostics(ctx context.Context, client *lsp.Client, filePath string, edits []TextEdit) (*EditResult, error) {
+	absFilePath, err := filepath.Abs(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("could not get absolute path for '%s': %w", filePath, err)
+	}
+	filePath = absFilePath
+
+	err = client.OpenFile(ctx, filePath)
+	if err != nil {
+		return nil, fmt.Errorf("could not open file '%s': %w", filePath, err)
+	}
+
+	uri := protocol.DocumentUri("file://" + filePath)
+
+	// Diagnostics of the file as it is before the edit
+	baseline, err := client.WaitForDiagnostics(ctx, filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get diagnostics before the edit: %w", err)
+	}
+	before := client.GetAllDiagnostics()
+	before[uri] = baseline
+
+	result, err := ApplyTextEdits(ctx, client, filePath, edits)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := client.NotifyChange(ctx, filePath); err != nil {
+		return nil, fmt.Errorf("failed to notify the server of the edit: %w", err)
+	}
+	current, err := client.WaitForDiagnostics(ctx, filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get diagnostics after the edit: %w", err)
+	}
+	after := client.GetAllDiagnostics()
+	after[uri] = current
+
+	uris := make(map[protocol.DocumentUri]bool)
+	for u := range before {
+		uris[u] = true
+	}
+	for u := range after {
+		uris[u] = true
+	}
+	sorted := make([]protocol.DocumentUri, 0, len(uris))
+	for u := range uris {
+		sorted = append(sorted, u)
+	}
+	// The edited file first, then the others by path
+	sort.Slice(sorted, func(i, j int) bool {
+		if (sorted[i] == uri) != (sorted[j] == uri) {
+			return sorted[i] == uri
+		}
+		return sorted[i] < sorted[j]
+	})
+
+	change := &DiagnosticsChange{
+		Current:    newDiagnostics(uri, current),
+		Introduced: []Diagnostic{},
+		Resolved:   []Diagnostic{},
+	}
+	for _, u := range sorted {
+		introduced, resolved := diffDiagnostics(before[u], after[u])
+		change.Introduced = append(change.Introduced, newDiagnostics(u, introduced)...)
+		change.Resolved = append(change.Resolved, newDiagnostics(u, resolved)...)
+	}
+
+	result.Diagnostics = change
+	return result, nil
+}
+
+// diffDiagnostics compares the diagnostics of a file before and after an edit.
+// Edits move diagnostics around, so they are matched by severity, source, code
+// and message rather than by position. Diagnostics are returned in position
+// order, introduced ones at their new position and resolved ones at their old.
+func diffDiagnostics(before, after []protocol.Diagnostic) (introduced, resolved []protocol.Diagnostic) {
+	key := func(diag protocol.Diagnostic) string {
+		return fmt.Sprintf("%d\x00%s\x00%v\x00%s", diag.Severity, diag.Source, diag.Code, diag.Message)
+	}
+
+	remaining := make(map[string]int)
+	for _, diag := range before {
+		remaining[key(diag)]++
+	}
+	for _, diag := range after {
+		k := key(diag)
+		if remaining[k] > 0 {
+			remaining[k]--
+			continue
+		}
+		introduced = append(introduced, diag)
+	}
+
+	// Whatever was not matched by a diagnostic after the edit has been resolved
+	for _, diag := range before {
+		k := key(diag)
+		if remaining[k] > 0 {
+			remaining[k]--
+			resolved = append(resolved, diag)
+		}
+	}
+
+	byPosition := func(diags []protocol.Diagnostic) {
+		sort.SliceStable(diags, func(i, j int) bool {
+			a, b := diags[i].Range.Start, diags[j].Range.Start
+			if a.Line != b.Line {
+				return a.Line < b.Line
+			}
+			return a.Character < b.Character
+		})
+	}
+	byPosition(introduced)
+	byPosition(resolved)
+	return introduced, resolved
+}
+
+// formatDiagnosticLine formats a diagnostic as "line:col [SEVERITY] message (source code)".
+func formatDiagnosticLine(diag Diagnostic) string {
+	details := strings.TrimSpace(diag.Source + " " + diag.Code)
+	if details != "" {
+		details = " (" + details + ")"
+	}
+	return fmt.Sprintf("%d:%d [%s] %s%s",
+		diag.Location.Line,
+		diag.Location.Column,
+		strings.ToUpper(diag.Severity),
+		strings.ReplaceAll(diag.Message, "\n", " "),
+		details)
+}
@@ -0,0 +1,55 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func diagnosticAt(line uint32, severity protocol.DiagnosticSeverity, message string) protocol.Diagnostic {
+	return protocol.Diagnostic{
+		Range:    protocol.Range{Start: protocol.Position{Line: line}, End: protocol.Position{Line: line}},
+		Severity: severity,
+		Source:   "compiler",
+		Message:  message,
+	}
+}
+
+func TestDiffDiagnostics(t *testing.T) {
+	before := []protocol.Diagnostic{
+		diagnosticAt(3, protocol.SeverityError, "undefined: foo"),
+		diagnosticAt(10, protocol.SeverityWarning, "unused variable x"),
+		diagnosticAt(12, protocol.SeverityWarning, "unused variable x"),
+	}
+	// The edit inserted two lines above, fixed foo, and broke bar
+	after := []protocol.Diagnostic{
+		diagnosticAt(5, protocol.SeverityError, "undefined: bar"),
+		diagnosticAt(12, protocol.SeverityWarning, "unused variable x"),
+		diagnosticAt(14, protocol.SeverityWarning, "unused variable x"),
+	}
+
+	introduced, resolved := diffDiagnostics(before, after)
+
+	require.Len(t, introduced, 1)
+	assert.Equal(t, "undefined: bar", introduced[0].Message)
+	require.Len(t, resolved, 1)
+	assert.Equal(t, "undefined: foo", resolved[0].Message)
+}
+
+func TestDiffDiagnosticsDuplicates(t *testing.T) {
+	before := []protocol.Diagnostic{
+		diagnosticAt(1, protocol.SeverityWarning, "unused variable x"),
+	}
+	after := []protocol.Diagnostic{
+		diagnosticAt(1, protocol.SeverityWarning, "unused variable x"),
+		diagnosticAt(4, protocol.SeverityWarning, "unused variable x"),
+	}
+
+	introduced, resolved := diffDiagnostics(before, after)
+
+	require.Len(t, introduced, 1)
+	assert.Equal(t, uint32(4), introduced[0].Range.Start.Line)
+	assert.Empty(t, resolved)
+}
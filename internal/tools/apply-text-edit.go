@@ -50,19 +50,35 @@ type TextEdit struct {
 	BracketTypes []string     `json:"bracketTypes,omitempty" jsonschema:"description=Types of brackets to check (e.g., '()', '{}', '[]'). Defaults if empty."`
 }
 
+// EditResult is the result of apply_text_edit.
+type EditResult struct {
+	Path    string `json:"path"`
+	Applied bool   `json:"applied"`
+	// Diagnostics is only set if diagnostics were requested
+	Diagnostics *DiagnosticsChange `json:"diagnostics,omitempty"`
+}
+
+func (r *EditResult) Text() string {
+	text := "Successfully applied text edits.\nWARNING: line numbers may have changed. Re-read code before applying additional edits."
+	if r.Diagnostics != nil {
+		text += "\n\n" + r.Diagnostics.text(r.Path)
+	}
+	return text
+}
+
 // ApplyTextEdits applies a series of text edits to a file.
 // It now accepts a FileOpener interface instead of a concrete *lsp.Client.
-func ApplyTextEdits(ctx context.Context, opener FileOpener, filePath string, edits []TextEdit) (string, error) {
+func ApplyTextEdits(ctx context.Context, opener FileOpener, filePath string, edits []TextEdit) (*EditResult, error) {
 	// Ensure filePath is absolute
 	absFilePath, err := filepath.Abs(filePath)
 	if err != nil {
-		return "", fmt.Errorf("could not get absolute path for '%s': %w", filePath, err)
+		return nil, fmt.Errorf("could not get absolute path for '%s': %w", filePath, err)
 	}
 	filePath = absFilePath // Use absolute path from now on
 
 	err = opener.OpenFile(ctx, filePath) // Use the opener interface
 	if err != nil {
-		return "", fmt.Errorf("could not open file '%s': %w", filePath, err)
+		return nil, fmt.Errorf("could not open file '%s': %w", filePath, err)
 	}
 
 	// Sort edits by line number in descending order to process from bottom to top
@@ -76,13 +92,13 @@ func ApplyTextEdits(ctx context.Context, opener FileOpener, filePath string, edi
 	for _, edit := range edits {
 		// --- Parameter Conflict Check ---
 		if edit.IsRegex && edit.NewText != "" {
-			return "", fmt.Errorf("invalid edit parameters for line %d: cannot provide both IsRegex=true and non-empty NewText", edit.StartLine)
+			return nil, fmt.Errorf("invalid edit parameters for line %d: cannot provide both IsRegex=true and non-empty NewText", edit.StartLine)
 		}
 		// --- End Parameter Conflict Check ---
 
 		rng, err := getRange(edit.StartLine, edit.EndLine, filePath)
 		if err != nil {
-			return "", fmt.Errorf("invalid position: %v", err)
+			return nil, fmt.Errorf("invalid position: %v", err)
 		}
 
 		// --- Bracket Guard Check ---
@@ -91,11 +107,11 @@ func ApplyTextEdits(ctx context.Context, opener FileOpener, filePath string, edi
 			contentBytes, readErr := os.ReadFile(filePath)
 			if readErr != nil {
 				// Log or handle error? For now, maybe return error as it's critical for the check.
-				return "", fmt.Errorf("failed to read file for bracket check: %w", readErr)
+				return nil, fmt.Errorf("failed to read file for bracket check: %w", readErr)
 			}
 			if guardErr := checkBracketBalance(ctx, filePath, edit, contentBytes); guardErr != nil {
 				// If the check fails, return the specific bracket guard error
-				return "", guardErr
+				return nil, guardErr
 			}
 		}
 		// --- End Bracket Guard Check ---
@@ -103,7 +119,7 @@ func ApplyTextEdits(ctx context.Context, opener FileOpener, filePath string, edi
 		// Handle Regex Replace first
 		if edit.IsRegex && edit.Type == Replace {
 			if edit.RegexPattern == "" {
-				return "", fmt.Errorf("regex pattern cannot be empty when isRegex is true for edit starting at line %d", edit.StartLine)
+				return nil, fmt.Errorf("regex pattern cannot be empty when isRegex is true for edit starting at line %d", edit.StartLine)
 			}
 
 			// Read file content to get the text within the range
@@ -111,7 +127,7 @@ func ApplyTextEdits(ctx context.Context, opener FileOpener, filePath string, edi
 			if err != nil {
 				// Note: This reads the file potentially multiple times in a loop if many regex edits exist.
 				// Could be optimized by reading once before the loop if performance becomes an issue.
-				return "", fmt.Errorf("failed to read file for regex replace: %w", err)
+				return nil, fmt.Errorf("failed to read file for regex replace: %w", err)
 			}
 			contentStr := string(contentBytes)
 
@@ -130,7 +146,7 @@ func ApplyTextEdits(ctx context.Context, opener FileOpener, filePath string, edi
 			if startIdx > endIdx {
 				// If start is beyond end after clamping, it implies an invalid input range or targeting EOF in a weird way.
 				// For regex replace, we need a valid content range.
-				return "", fmt.Errorf("invalid range for regex replace: start line %d > end line %d (after bounds check)", edit.StartLine, edit.EndLine)
+				return nil, fmt.Errorf("invalid range for regex replace: start line %d > end line %d (after bounds check)", edit.StartLine, edit.EndLine)
 			}
 
 			// Extract the content within the specified lines
@@ -139,7 +155,7 @@ func ApplyTextEdits(ctx context.Context, opener FileOpener, filePath string, edi
 			// Compile the regex
 			re, err := regexp.Compile(edit.RegexPattern)
 			if err != nil {
-				return "", fmt.Errorf("invalid regex pattern '%s' for edit starting at line %d: %w", edit.RegexPattern, edit.StartLine, err)
+				return nil, fmt.Errorf("invalid regex pattern '%s' for edit starting at line %d: %w", edit.RegexPattern, edit.StartLine, err)
 			}
 
 			// Perform the replacement within the extracted content
@@ -174,7 +190,7 @@ func ApplyTextEdits(ctx context.Context, opener FileOpener, filePath string, edi
 			}
 		default:
 			// Should not happen if JSON schema validation works, but good to have
-			return "", fmt.Errorf("unknown edit type '%s' for edit starting at line %d", edit.Type, edit.StartLine)
+			return nil, fmt.Errorf("unknown edit type '%s' for edit starting at line %d", edit.Type, edit.StartLine)
 		}
 		textEdits = append(textEdits, currentEdit)
 	}
@@ -186,10 +202,10 @@ func ApplyTextEdits(ctx context.Context, opener FileOpener, filePath string, edi
 	}
 
 	if err := utilities.ApplyWorkspaceEdit(edit); err != nil {
-		return "", fmt.Errorf("failed to apply text edits: %v", err)
+		return nil, fmt.Errorf("failed to apply text edits: %v", err)
 	}
 
-	return "Successfully applied text edits.\nWARNING: line numbers may have changed. Re-read code before applying additional edits.", nil
+	return &EditResult{Path: filePath, Applied: true}, nil
 }
 
 // getRange now handles EOF insertions and is more precise about character positions
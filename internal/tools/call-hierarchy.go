@@ -0,0 +1,251 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// Call hierarchy directions accepted by GetCallHierarchy.
+const (
+	CallsIncoming = "incoming"
+	CallsOutgoing = "outgoing"
+	CallsBoth     = "both"
+)
+
+const defaultCallHierarchyDepth = 3
+
+// CallHierarchyResult is the result of call_hierarchy.
+type CallHierarchyResult struct {
+	Position Location `json:"position"`
+	// Direction is "incoming", "outgoing" or "both"
+	Direction string `json:"direction"`
+	MaxDepth  int    `json:"maxDepth"`
+	// Items are the symbols at the position, usually one
+	Items []CallHierarchy `json:"items"`
+}
+
+// CallHierarchy is the call tree of one symbol.
+type CallHierarchy struct {
+	Item HierarchyItem `json:"item"`
+	// Incoming and Outgoing are omitted if not requested
+	Incoming []CallNode `json:"incoming,omitempty"`
+	Outgoing []CallNode `json:"outgoing,omitempty"`
+}
+
+// CallNode is a caller or callee in a call tree.
+type CallNode struct {
+	Item HierarchyItem `json:"item"`
+	// CallSites are where the calls are made, in the caller's file
+	CallSites []Location `json:"callSites"`
+	// AlreadyShown marks items that appear earlier in the tree; they are not expanded again
+	AlreadyShown bool `json:"alreadyShown,omitempty"`
+	// Calls are the callers, or callees, of Item
+	Calls []CallNode `json:"calls,omitempty"`
+}
+
+// HierarchyItem is a symbol in a call or type hierarchy.
+type HierarchyItem struct {
+	Name     string   `json:"name"`
+	Kind     string   `json:"kind"`
+	Detail   string   `json:"detail,omitempty"`
+	Location Location `json:"location"`
+}
+
+func (r *CallHierarchyResult) Text() string {
+	if len(r.Items) == 0 {
+		return fmt.Sprintf("No call hierarchy item found at %s", r.Position)
+	}
+
+	var output strings.Builder
+	for _, hierarchy := range r.Items {
+		output.WriteString(strings.Repeat("=", 80) + "\n")
+		output.WriteString(fmt.Sprintf("Call hierarchy for %s\n", hierarchy.Item))
+		output.WriteString(strings.Repeat("=", 80) + "\n")
+
+		if r.Direction == CallsIncoming || r.Direction == CallsBoth {
+			output.WriteString(fmt.Sprintf("\nIncoming calls (max depth %d):\n", r.MaxDepth))
+			if len(hierarchy.Incoming) == 0 {
+				output.WriteString("  (no callers found)\n")
+			}
+			writeCallNodes(&output, hierarchy.Incoming, 1)
+		}
+
+		if r.Direction == CallsOutgoing || r.Direction == CallsBoth {
+			output.WriteString(fmt.Sprintf("\nOutgoing calls (max depth %d):\n", r.MaxDepth))
+			if len(hierarchy.Outgoing) == 0 {
+				output.WriteString("  (no callees found)\n")
+			}
+			writeCallNodes(&output, hierarchy.Outgoing, 1)
+		}
+		output.WriteString("\n")
+	}
+
+	return output.String()
+}
+
+// writeCallNodes writes each node of the tree followed by its call sites.
+func writeCallNodes(output *strings.Builder, nodes []CallNode, depth int) {
+	indent := strings.Repeat("  ", depth)
+	for _, node := range nodes {
+		suffix := ""
+		if node.AlreadyShown {
+			suffix = " (already shown)"
+		}
+		output.WriteString(fmt.Sprintf("%s- %s%s\n", indent, node.Item, suffix))
+
+		for _, site := range node.CallSites {
+			output.WriteString(fmt.Sprintf("%s    call site: %s\n", indent, site))
+		}
+		writeCallNodes(output, node.Calls, depth+1)
+	}
+}
+
+// String formats an item as "Name (Kind) path:line".
+func (item HierarchyItem) String() string {
+	detail := ""
+	if item.Detail != "" {
+		detail = " [" + item.Detail + "]"
+	}
+	return fmt.Sprintf("%s (%s)%s %s:%d",
+		item.Name,
+		item.Kind,
+		detail,
+		item.Location.Path,
+		item.Location.Line)
+}
+
+// GetCallHierarchy returns the incoming and/or outgoing call tree of the symbol at
+// the given position or with the given name, up to maxDepth levels deep.
+func GetCallHierarchy(ctx context.Context, client *lsp.Client, filePath string, line, column int, symbolName string, direction string, maxDepth int) (*CallHierarchyResult, error) {
+	if direction == "" {
+		direction = CallsIncoming
+	}
+	if direction != CallsIncoming && direction != CallsOutgoing && direction != CallsBoth {
+		return nil, fmt.Errorf("invalid direction: %s. Must be 'incoming', 'outgoing' or 'both'", direction)
+	}
+	if maxDepth < 1 {
+		maxDepth = defaultCallHierarchyDepth
+	}
+
+	position, err := resolvePosition(ctx, client, filePath, line, column, symbolName)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := client.PrepareCallHierarchy(ctx, protocol.CallHierarchyPrepareParams{
+		TextDocumentPositionParams: position,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare call hierarchy: %w", err)
+	}
+
+	result := &CallHierarchyResult{
+		Position:  newPosition(position.TextDocument.URI, position.Position),
+		Direction: direction,
+		MaxDepth:  maxDepth,
+		Items:     []CallHierarchy{},
+	}
+	for _, item := range items {
+		hierarchy := CallHierarchy{Item: newCallHierarchyItem(item)}
+
+		if direction == CallsIncoming || direction == CallsBoth {
+			visited := map[string]bool{callHierarchyItemKey(item): true}
+			hierarchy.Incoming, err = incomingCalls(ctx, client, item, 1, maxDepth, visited)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if direction == CallsOutgoing || direction == CallsBoth {
+			visited := map[string]bool{callHierarchyItemKey(item): true}
+			hierarchy.Outgoing, err = outgoingCalls(ctx, client, item, 1, maxDepth, visited)
+			if err != nil {
+				return nil, err
+			}
+		}
+		result.Items = append(result.Items, hierarchy)
+	}
+
+	return result, nil
+}
+
+// incomingCalls returns the callers of item, each with its own callers.
+// Items in visited are returned but not expanded again, which breaks cycles.
+func incomingCalls(ctx context.Context, client *lsp.Client, item protocol.CallHierarchyItem, depth, maxDepth int, visited map[string]bool) ([]CallNode, error) {
+	calls, err := client.IncomingCalls(ctx, protocol.CallHierarchyIncomingCallsParams{Item: item})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get incoming calls for %s: %w", item.Name, err)
+	}
+
+	var nodes []CallNode
+	for _, call := range calls {
+		key := callHierarchyItemKey(call.From)
+		node := newCallNode(call.From, call.From.URI, call.FromRanges, visited[key])
+		if !visited[key] && depth < maxDepth {
+			visited[key] = true
+			node.Calls, err = incomingCalls(ctx, client, call.From, depth+1, maxDepth, visited)
+			if err != nil {
+				return nil, err
+			}
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes, nil
+}
+
+// outgoingCalls returns the callees of item, each with its own callees.
+// Items in visited are returned but not expanded again, which breaks cycles.
+func outgoingCalls(ctx context.Context, client *lsp.Client, item protocol.CallHierarchyItem, depth, maxDepth int, visited map[string]bool) ([]CallNode, error) {
+	calls, err := client.OutgoingCalls(ctx, protocol.CallHierarchyOutgoingCallsParams{Item: item})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get outgoing calls for %s: %w", item.Name, err)
+	}
+
+	var nodes []CallNode
+	for _, call := range calls {
+		key := callHierarchyItemKey(call.To)
+		// For outgoing calls the call sites are in the calling item's document
+		node := newCallNode(call.To, item.URI, call.FromRanges, visited[key])
+		if !visited[key] && depth < maxDepth {
+			visited[key] = true
+			node.Calls, err = outgoingCalls(ctx, client, call.To, depth+1, maxDepth, visited)
+			if err != nil {
+				return nil, err
+			}
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes, nil
+}
+
+// newCallNode converts an item of the tree and its call sites.
+func newCallNode(item protocol.CallHierarchyItem, callSiteURI protocol.DocumentUri, callSites []protocol.Range, seen bool) CallNode {
+	node := CallNode{
+		Item:         newCallHierarchyItem(item),
+		CallSites:    []Location{},
+		AlreadyShown: seen,
+	}
+	for _, rng := range callSites {
+		node.CallSites = append(node.CallSites, newPosition(callSiteURI, rng.Start))
+	}
+	return node
+}
+
+// newCallHierarchyItem converts a protocol call hierarchy item.
+func newCallHierarchyItem(item protocol.CallHierarchyItem) HierarchyItem {
+	return HierarchyItem{
+		Name:     item.Name,
+		Kind:     symbolKindToString(item.Kind),
+		Detail:   item.Detail,
+		Location: newLocation(item.URI, item.SelectionRange),
+	}
+}
+
+// callHierarchyItemKey identifies an item for cycle detection.
+func callHierarchyItemKey(item protocol.CallHierarchyItem) string {
+	return fmt.Sprintf("%s:%d:%d", item.URI, item.SelectionRange.Start.Line, item.SelectionRange.Start.Character)
+}
@@ -0,0 +1,142 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newCallGraphClient returns a client whose server knows the functions main,
+// run and step in path, where main calls run, run calls step and step calls run.
+func newCallGraphClient(t *testing.T, path string) *lsp.Client {
+	uri := protocol.DocumentUri("file://" + path)
+	item := func(name string, line uint32) protocol.CallHierarchyItem {
+		rng := protocol.Range{Start: protocol.Position{Line: line, Character: 5}, End: protocol.Position{Line: line, Character: 5 + uint32(len(name))}}
+		return protocol.CallHierarchyItem{Name: name, Kind: protocol.Function, URI: uri, Range: rng, SelectionRange: rng}
+	}
+	items := map[string]protocol.CallHierarchyItem{"main": item("main", 2), "run": item("run", 6), "step": item("step", 10)}
+	calls := map[string][]string{"main": {"run"}, "run": {"step"}, "step": {"run"}}
+	site := func(line uint32) []protocol.Range {
+		return []protocol.Range{{Start: protocol.Position{Line: line + 1, Character: 1}, End: protocol.Position{Line: line + 1, Character: 4}}}
+	}
+
+	client, _ := newFakeClient(t, protocol.ServerCapabilities{}, map[string]fakeHandler{
+		"textDocument/prepareCallHierarchy": func(json.RawMessage) (any, error) {
+			return []protocol.CallHierarchyItem{items["run"]}, nil
+		},
+		"callHierarchy/incomingCalls": func(params json.RawMessage) (any, error) {
+			var p protocol.CallHierarchyIncomingCallsParams
+			require.NoError(t, json.Unmarshal(params, &p))
+			var result []protocol.CallHierarchyIncomingCall
+			for _, caller := range []string{"main", "run", "step"} {
+				for _, callee := range calls[caller] {
+					if callee == p.Item.Name {
+						result = append(result, protocol.CallHierarchyIncomingCall{From: items[caller], FromRanges: site(items[caller].Range.Start.Line)})
+					}
+				}
+			}
+			return result, nil
+		},
+		"callHierarchy/outgoingCalls": func(params json.RawMessage) (any, error) {
+			var p protocol.CallHierarchyOutgoingCallsParams
+			require.NoError(t, json.Unmarshal(params, &p))
+			var result []protocol.CallHierarchyOutgoingCall
+			for _, callee := range calls[p.Item.Name] {
+				result = append(result, protocol.CallHierarchyOutgoingCall{To: items[callee], FromRanges: site(p.Item.Range.Start.Line)})
+			}
+			return result, nil
+		},
+	})
+	return client
+}
+
+// callTree flattens call nodes into "name" entries indented by depth, with
+// nodes that are not expanded again marked by "*".
+func callTree(nodes []CallNode, indent string) []string {
+	var lines []string
+	for _, node := range nodes {
+		line := indent + node.Item.Name
+		if node.AlreadyShown {
+			line += "*"
+		}
+		lines = append(lines, line)
+		lines = append(lines, callTree(node.Calls, indent+"  ")...)
+	}
+	return lines
+}
+
+func TestGetCallHierarchy(t *testing.T) {
+	path := writeTestFile(t, "main.go", "package main\n")
+	client := newCallGraphClient(t, path)
+
+	tests := []struct {
+		name      string
+		direction string
+		maxDepth  int
+		incoming  []string
+		outgoing  []string
+	}{
+		{
+			name:      "cycles are not expanded again",
+			direction: CallsBoth,
+			maxDepth:  5,
+			incoming:  []string{"main", "step", "  run*"},
+			outgoing:  []string{"step", "  run*"},
+		},
+		{
+			name:      "depth limit",
+			direction: CallsIncoming,
+			maxDepth:  1,
+			incoming:  []string{"main", "step"},
+		},
+		{
+			name:      "outgoing only",
+			direction: CallsOutgoing,
+			maxDepth:  2,
+			outgoing:  []string{"step", "  run*"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := GetCallHierarchy(context.Background(), client, path, 7, 6, "", tt.direction, tt.maxDepth)
+			require.NoError(t, err)
+			require.Len(t, result.Items, 1)
+			assert.Equal(t, "run", result.Items[0].Item.Name)
+			assert.Equal(t, tt.incoming, callTree(result.Items[0].Incoming, ""))
+			assert.Equal(t, tt.outgoing, callTree(result.Items[0].Outgoing, ""))
+		})
+	}
+
+	_, err := GetCallHierarchy(context.Background(), client, path, 7, 6, "", "sideways", 1)
+	assert.Error(t, err)
+}
+
+func TestGetCallHierarchyCallSites(t *testing.T) {
+	path := writeTestFile(t, "main.go", "package main\n")
+	client := newCallGraphClient(t, path)
+
+	result, err := GetCallHierarchy(context.Background(), client, path, 7, 6, "", CallsBoth, 1)
+	require.NoError(t, err)
+	hierarchy := result.Items[0]
+
+	// Incoming call sites are in the caller, outgoing ones in the item itself
+	assert.Equal(t, []Location{{Path: path, Line: 4, Column: 2}}, hierarchy.Incoming[0].CallSites)
+	assert.Equal(t, []Location{{Path: path, Line: 8, Column: 2}}, hierarchy.Outgoing[0].CallSites)
+
+	assert.Equal(t, "================================================================================\n"+
+		"Call hierarchy for run (Function) "+path+":7\n"+
+		"================================================================================\n"+
+		"\nIncoming calls (max depth 1):\n"+
+		"  - main (Function) "+path+":3\n"+
+		"      call site: "+path+":4:2\n"+
+		"  - step (Function) "+path+":11\n"+
+		"      call site: "+path+":12:2\n"+
+		"\nOutgoing calls (max depth 1):\n"+
+		"  - step (Function) "+path+":11\n"+
+		"      call site: "+path+":8:2\n\n", result.Text())
+}
@@ -0,0 +1,328 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/isaacphi/mcp-language-server/internal/utilities"
+)
+
+// getCodeActions requests the code actions for a line range of a file. Only
+// cached diagnostics overlapping the range and matching diagnosticFilter are sent
+// as context, and only actions of the given kinds are requested. Bare Commands
+// returned by the server are wrapped in a CodeAction so callers handle one type.
+func getCodeActions(ctx context.Context, client *lsp.Client, filePath string, startLine, endLine int, diagnosticFilter string, kinds []string) ([]protocol.CodeAction, error) {
+	if startLine < 1 {
+		startLine = 1
+	}
+	if endLine < startLine {
+		endLine = startLine
+	}
+
+	rng, err := getRange(startLine, endLine, filePath)
+	if err != nil {
+		return nil, fmt.Errorf("invalid range: %v", err)
+	}
+
+	uri := protocol.DocumentUri("file://" + filePath)
+
+	var diagnostics []protocol.Diagnostic
+	for _, diag := range client.GetFileDiagnostics(uri) {
+		if !rangesIntersect(diag.Range, rng) || !diagnosticMatches(diag, diagnosticFilter) {
+			continue
+		}
+		diagnostics = append(diagnostics, diag)
+	}
+	if diagnostics == nil {
+		// The protocol requires an array here, not null
+		diagnostics = []protocol.Diagnostic{}
+	}
+
+	var only []protocol.CodeActionKind
+	for _, kind := range kinds {
+		only = append(only, protocol.CodeActionKind(kind))
+	}
+
+	result, err := client.CodeAction(ctx, protocol.CodeActionParams{
+		TextDocument: protocol.TextDocumentIdentifier{URI: uri},
+		Range:        rng,
+		Context: protocol.CodeActionContext{
+			Diagnostics: diagnostics,
+			Only:        only,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get code actions: %w", err)
+	}
+
+	var actions []protocol.CodeAction
+	for _, item := range result {
+		var action protocol.CodeAction
+		switch v := item.Value.(type) {
+		case protocol.CodeAction:
+			action = v
+		case protocol.Command:
+			action = protocol.CodeAction{Title: v.Title, Command: &v}
+		default:
+			continue
+		}
+
+		// Drop fixes for diagnostics the caller filtered out
+		if diagnosticFilter != "" && len(action.Diagnostics) > 0 {
+			matches := false
+			for _, diag := range action.Diagnostics {
+				if diagnosticMatches(diag, diagnosticFilter) {
+					matches = true
+					break
+				}
+			}
+			if !matches {
+				continue
+			}
+		}
+		actions = append(actions, action)
+	}
+
+	return actions, nil
+}
+
+// CodeActionsResult is the result of code_actions.
+type CodeActionsResult struct {
+	Path    string       `json:"path"`
+	Actions []CodeAction `json:"actions"`
+}
+
+// CodeAction is a quick fix, refactoring or source action.
+type CodeAction struct {
+	// Index is the 1-based index to pass to apply_code_action
+	Index     int    `json:"index"`
+	Title     string `json:"title"`
+	Kind      string `json:"kind,omitempty"`
+	Preferred bool   `json:"preferred,omitempty"`
+	// Fixes are the diagnostics the action resolves
+	Fixes []Diagnostic `json:"fixes,omitempty"`
+	// Disabled is the reason the action cannot be applied, if it cannot
+	Disabled string `json:"disabled,omitempty"`
+}
+
+func (r *CodeActionsResult) Text() string {
+	if len(r.Actions) == 0 {
+		return fmt.Sprintf("No code actions available for %s", r.Path)
+	}
+
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("Code actions for %s:\n", r.Path))
+	output.WriteString(strings.Repeat("=", 80) + "\n\n")
+
+	for _, action := range r.Actions {
+		kind := ""
+		if action.Kind != "" {
+			kind = fmt.Sprintf(" (%s)", action.Kind)
+		}
+		preferred := ""
+		if action.Preferred {
+			preferred = " [preferred]"
+		}
+		output.WriteString(fmt.Sprintf("[%d] %s%s%s\n", action.Index, action.Title, kind, preferred))
+
+		for _, diag := range action.Fixes {
+			output.WriteString(fmt.Sprintf("    Fixes: [%s] Line %d: %s\n",
+				strings.ToUpper(diag.Severity),
+				diag.Location.Line,
+				diag.Message))
+		}
+		if action.Disabled != "" {
+			output.WriteString(fmt.Sprintf("    Disabled: %s\n", action.Disabled))
+		}
+		output.WriteString("\n")
+	}
+
+	return output.String()
+}
+
+// ListCodeActions lists the code actions (quick fixes, refactorings and source
+// actions) available for a line range of a file.
+func ListCodeActions(ctx context.Context, client *lsp.Client, filePath string, startLine, endLine int, diagnosticFilter string, kinds []string) (*CodeActionsResult, error) {
+	absFilePath, err := filepath.Abs(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("could not get absolute path for '%s': %w", filePath, err)
+	}
+	filePath = absFilePath
+
+	err = client.OpenFile(ctx, filePath)
+	if err != nil {
+		return nil, fmt.Errorf("could not open file '%s': %w", filePath, err)
+	}
+
+	actions, err := getCodeActions(ctx, client, filePath, startLine, endLine, diagnosticFilter, kinds)
+	if err != nil {
+		return nil, err
+	}
+
+	uri := protocol.DocumentUri("file://" + filePath)
+	result := &CodeActionsResult{Path: filePath, Actions: []CodeAction{}}
+	for i, action := range actions {
+		item := CodeAction{
+			Index:     i + 1,
+			Title:     action.Title,
+			Kind:      string(action.Kind),
+			Preferred: action.IsPreferred,
+		}
+		if len(action.Diagnostics) > 0 {
+			item.Fixes = newDiagnostics(uri, action.Diagnostics)
+		}
+		if action.Disabled != nil {
+			item.Disabled = action.Disabled.Reason
+		}
+		result.Actions = append(result.Actions, item)
+	}
+
+	return result, nil
+}
+
+// AppliedCodeActionResult is the result of apply_code_action.
+type AppliedCodeActionResult struct {
+	Title string `json:"title"`
+	// Changed are the paths of the files the action edited
+	Changed []string `json:"changed"`
+	// Command is the command the action executed, if any
+	Command string `json:"command,omitempty"`
+}
+
+func (r *AppliedCodeActionResult) Text() string {
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("Applied code action: %s\n", r.Title))
+	for _, path := range r.Changed {
+		output.WriteString(fmt.Sprintf("Changed: %s\n", path))
+	}
+	if r.Command != "" {
+		output.WriteString(fmt.Sprintf("Executed command: %s\n", r.Command))
+	}
+
+	output.WriteString("WARNING: line numbers may have changed. Re-read code before applying additional edits.")
+	return output.String()
+}
+
+// ApplyCodeAction requests the code actions for a line range of a file with the
+// same filters as ListCodeActions, resolves the action at the given 1-based
+// index if needed, applies its edit and runs its command.
+func ApplyCodeAction(ctx context.Context, client *lsp.Client, filePath string, startLine, endLine int, diagnosticFilter string, kinds []string, index int) (*AppliedCodeActionResult, error) {
+	absFilePath, err := filepath.Abs(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("could not get absolute path for '%s': %w", filePath, err)
+	}
+	filePath = absFilePath
+
+	err = client.OpenFile(ctx, filePath)
+	if err != nil {
+		return nil, fmt.Errorf("could not open file '%s': %w", filePath, err)
+	}
+
+	actions, err := getCodeActions(ctx, client, filePath, startLine, endLine, diagnosticFilter, kinds)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(actions) == 0 {
+		return nil, fmt.Errorf("No code actions available for %s", filePath)
+	}
+
+	if index < 1 || index > len(actions) {
+		return nil, fmt.Errorf("Invalid code action index: %d. Available range: 1-%d", index, len(actions))
+	}
+
+	return applyCodeAction(ctx, client, actions[index-1])
+}
+
+// applyCodeAction resolves and applies an action, reporting the files that
+// changed and the command that ran.
+func applyCodeAction(ctx context.Context, client *lsp.Client, action protocol.CodeAction) (*AppliedCodeActionResult, error) {
+	action, err := resolveAndApplyCodeAction(ctx, client, action)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &AppliedCodeActionResult{Title: action.Title, Changed: []string{}}
+	if action.Edit != nil {
+		result.Changed = append(result.Changed, workspaceEditPaths(*action.Edit)...)
+	}
+	if action.Command != nil {
+		result.Command = action.Command.Command
+	}
+
+	return result, nil
+}
+
+// resolveAndApplyCodeAction resolves an action if it has no edit yet and the
+// server can resolve code actions, applies the edit and executes the command.
+// It returns the resolved action.
+func resolveAndApplyCodeAction(ctx context.Context, client *lsp.Client, action protocol.CodeAction) (protocol.CodeAction, error) {
+	if action.Disabled != nil {
+		return action, fmt.Errorf("Code action '%s' is disabled: %s", action.Title, action.Disabled.Reason)
+	}
+
+	if action.Edit == nil && supportsCodeActionResolve(client) {
+		resolved, err := client.ResolveCodeAction(ctx, action)
+		if err != nil {
+			return action, fmt.Errorf("Failed to resolve code action: %v", err)
+		}
+		action = resolved
+	}
+
+	if action.Edit == nil && action.Command == nil {
+		return action, fmt.Errorf("Code action '%s' has no edit or command", action.Title)
+	}
+
+	if action.Edit != nil {
+		if err := utilities.ApplyWorkspaceEdit(*action.Edit); err != nil {
+			return action, fmt.Errorf("Failed to apply code action edit: %v", err)
+		}
+		notifyWorkspaceEdit(ctx, client, *action.Edit)
+	}
+
+	if action.Command != nil {
+		_, err := client.ExecuteCommand(ctx, protocol.ExecuteCommandParams{
+			Command:   action.Command.Command,
+			Arguments: action.Command.Arguments,
+		})
+		if err != nil {
+			return action, fmt.Errorf("Failed to execute code action command: %v", err)
+		}
+	}
+
+	return action, nil
+}
+
+// supportsCodeActionResolve reports whether the server can resolve code actions.
+func supportsCodeActionResolve(client *lsp.Client) bool {
+	options, ok := client.ServerCapabilities().CodeActionProvider.(map[string]interface{})
+	return ok && options["resolveProvider"] == true
+}
+
+// diagnosticMatches reports whether filter is empty or occurs in the
+// diagnostic's message or code.
+func diagnosticMatches(diag protocol.Diagnostic, filter string) bool {
+	if filter == "" {
+		return true
+	}
+	filter = strings.ToLower(filter)
+	if strings.Contains(strings.ToLower(diag.Message), filter) {
+		return true
+	}
+	return diag.Code != nil && strings.Contains(strings.ToLower(fmt.Sprintf("%v", diag.Code)), filter)
+}
+
+// rangesIntersect reports whether two ranges share at least one position.
+func rangesIntersect(a, b protocol.Range) bool {
+	if a.End.Line < b.Start.Line || (a.End.Line == b.Start.Line && a.End.Character < b.Start.Character) {
+		return false
+	}
+	if b.End.Line < a.Start.Line || (b.End.Line == a.Start.Line && b.End.Character < a.Start.Character) {
+		return false
+	}
+	return true
+}
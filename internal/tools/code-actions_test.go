@@ -0,0 +1,90 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRangesIntersect(t *testing.T) {
+	lines := func(start, end uint32) protocol.Range {
+		return protocol.Range{
+			Start: protocol.Position{Line: start},
+			End:   protocol.Position{Line: end, Character: 10},
+		}
+	}
+
+	assert.True(t, rangesIntersect(lines(1, 3), lines(2, 2)), "contained range")
+	assert.True(t, rangesIntersect(lines(1, 3), lines(3, 5)), "overlapping last line")
+	assert.True(t, rangesIntersect(lines(4, 4), lines(1, 9)), "containing range")
+	assert.False(t, rangesIntersect(lines(1, 2), lines(3, 4)), "range after")
+	assert.False(t, rangesIntersect(lines(5, 6), lines(3, 4)), "range before")
+}
+
+func TestDiagnosticMatches(t *testing.T) {
+	diag := protocol.Diagnostic{
+		Message: `"fmt" imported and not used`,
+		Code:    "UnusedImport",
+	}
+
+	assert.True(t, diagnosticMatches(diag, ""), "empty filter matches everything")
+	assert.True(t, diagnosticMatches(diag, "not used"), "message substring")
+	assert.True(t, diagnosticMatches(diag, "unusedimport"), "code is matched case-insensitively")
+	assert.False(t, diagnosticMatches(diag, "undefined"), "unrelated filter")
+}
+
+func TestApplyCodeActionResolves(t *testing.T) {
+	path := writeTestFile(t, "main.go", "package main\n\nvar x = 1\n")
+	uri := protocol.DocumentUri("file://" + path)
+	edit := &protocol.WorkspaceEdit{Changes: map[protocol.DocumentUri][]protocol.TextEdit{
+		uri: {{Range: protocol.Range{Start: protocol.Position{Line: 2, Character: 4}, End: protocol.Position{Line: 2, Character: 5}}, NewText: "y"}},
+	}}
+
+	tests := []struct {
+		name         string
+		capabilities protocol.ServerCapabilities
+		resolves     int
+		wantErr      string
+	}{
+		{
+			name:         "action without data is resolved",
+			capabilities: protocol.ServerCapabilities{CodeActionProvider: map[string]any{"resolveProvider": true}},
+			resolves:     1,
+		},
+		{
+			name:         "server without resolve support",
+			capabilities: protocol.ServerCapabilities{CodeActionProvider: true},
+			wantErr:      "Code action 'Rename x to y' has no edit or command",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, server := newFakeClient(t, tt.capabilities, map[string]fakeHandler{
+				"codeAction/resolve": func(params json.RawMessage) (any, error) {
+					var action protocol.CodeAction
+					require.NoError(t, json.Unmarshal(params, &action))
+					action.Edit = edit
+					return action, nil
+				},
+			})
+
+			result, err := applyCodeAction(context.Background(), client, protocol.CodeAction{Title: "Rename x to y"})
+			assert.Equal(t, tt.resolves, server.requestCount("codeAction/resolve"))
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, []string{path}, result.Changed)
+
+			content, err := os.ReadFile(path)
+			require.NoError(t, err)
+			assert.Equal(t, "package main\n\nvar y = 1\n", string(content))
+		})
+	}
+}
@@ -0,0 +1,272 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+const defaultCompletionLimit = 30
+
+// CompletionsResult is the result of complete_at.
+type CompletionsResult struct {
+	Position Location `json:"position"`
+	// Prefix is the text inserted at the position before completing
+	Prefix string           `json:"prefix,omitempty"`
+	Items  []CompletionItem `json:"items"`
+	// Total is the number of items before the limit was applied
+	Total int `json:"total"`
+	// Incomplete is set if the server would return more items for a longer prefix
+	Incomplete bool `json:"incomplete,omitempty"`
+}
+
+// CompletionItem is a completion offered by the server.
+type CompletionItem struct {
+	Label         string `json:"label"`
+	Kind          string `json:"kind,omitempty"`
+	Detail        string `json:"detail,omitempty"`
+	Deprecated    bool   `json:"deprecated,omitempty"`
+	Documentation string `json:"documentation,omitempty"`
+}
+
+func (r *CompletionsResult) Text() string {
+	location := r.Position.String()
+	if r.Prefix != "" {
+		location += fmt.Sprintf(" after inserting %q", r.Prefix)
+	}
+
+	if len(r.Items) == 0 {
+		return fmt.Sprintf("No completions available at %s", location)
+	}
+
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("Completions at %s:\n", location))
+	output.WriteString(strings.Repeat("=", 80) + "\n")
+
+	for i, item := range r.Items {
+		output.WriteString(fmt.Sprintf("%d. %s\n", i+1, item))
+		if item.Documentation != "" {
+			output.WriteString(indentLines(item.Documentation, "     ") + "\n")
+		}
+	}
+
+	if r.Total > len(r.Items) {
+		output.WriteString(fmt.Sprintf("\n%d more completions not shown. Use a longer prefix or a higher limit to narrow down.\n", r.Total-len(r.Items)))
+	}
+	if r.Incomplete {
+		output.WriteString("\nThe server reported the list as incomplete. Use a longer prefix for more results.\n")
+	}
+
+	return output.String()
+}
+
+// CompleteAt returns the completion items the language server offers at the given
+// 1-based position, ranked as an editor would show them. If prefix is set it is
+// inserted at the position in the server's in-memory copy of the file first, so
+// that e.g. a prefix of "client." lists the members of client. The file on disk
+// is never modified.
+func CompleteAt(ctx context.Context, client *lsp.Client, filePath string, line, column int, prefix string, limit int) (*CompletionsResult, error) {
+	if line < 1 {
+		return nil, fmt.Errorf("line is required")
+	}
+	if column < 1 {
+		column = 1
+	}
+	if limit < 1 {
+		limit = defaultCompletionLimit
+	}
+
+	absFilePath, err := filepath.Abs(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("could not get absolute path for '%s': %w", filePath, err)
+	}
+	filePath = absFilePath
+
+	err = client.OpenFile(ctx, filePath)
+	if err != nil {
+		return nil, fmt.Errorf("could not open file '%s': %w", filePath, err)
+	}
+
+	position := protocol.Position{
+		Line:      uint32(line - 1),
+		Character: uint32(column - 1),
+	}
+
+	if prefix != "" {
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read file: %w", err)
+		}
+
+		edited, end, err := insertAt(string(content), position, prefix)
+		if err != nil {
+			return nil, err
+		}
+		if err := client.NotifyContent(ctx, filePath, []byte(edited)); err != nil {
+			return nil, fmt.Errorf("failed to send prefix to language server: %w", err)
+		}
+		defer func() {
+			// Diagnostics published for the text with the prefix are stale. They
+			// are cleared first so those for the restored text are kept
+			client.ClearDiagnostics(protocol.DocumentUri("file://" + filePath))
+			// Sync the server with the unchanged file on disk again
+			if err := client.NotifyChange(ctx, filePath); err != nil {
+				log.Printf("Error restoring %s after completion: %v\n", filePath, err)
+			}
+		}()
+		position = end
+	}
+
+	completionContext := protocol.CompletionContext{TriggerKind: protocol.Invoked}
+	if prefix != "" {
+		if options := client.ServerCapabilities().CompletionProvider; options != nil {
+			last := prefix[len(prefix)-1:]
+			if slices.Contains(options.TriggerCharacters, last) {
+				completionContext = protocol.CompletionContext{
+					TriggerKind:      protocol.TriggerCharacter,
+					TriggerCharacter: last,
+				}
+			}
+		}
+	}
+
+	uri := protocol.DocumentUri("file://" + filePath)
+	result, err := client.Completion(ctx, protocol.CompletionParams{
+		TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+			TextDocument: protocol.TextDocumentIdentifier{URI: uri},
+			Position:     position,
+		},
+		Context: completionContext,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get completions: %w", err)
+	}
+
+	items, incomplete, err := result.Items()
+	if err != nil {
+		return nil, fmt.Errorf("failed to process completions: %w", err)
+	}
+
+	completions := &CompletionsResult{
+		Position:   Location{Path: filePath, Line: line, Column: column},
+		Prefix:     prefix,
+		Items:      []CompletionItem{},
+		Total:      len(items),
+		Incomplete: incomplete,
+	}
+	if len(items) == 0 {
+		return completions, nil
+	}
+
+	rankCompletionItems(items)
+	if len(items) > limit {
+		items = items[:limit]
+	}
+
+	canResolve := false
+	if options := client.ServerCapabilities().CompletionProvider; options != nil {
+		canResolve = options.ResolveProvider
+	}
+
+	for _, item := range items {
+		if canResolve && (item.Documentation == nil || item.Detail == "") {
+			resolved, err := client.ResolveCompletionItem(ctx, item)
+			if err != nil {
+				log.Printf("Error resolving completion item %s: %v\n", item.Label, err)
+			} else {
+				item = resolved
+			}
+		}
+		completions.Items = append(completions.Items, newCompletionItem(item))
+	}
+
+	return completions, nil
+}
+
+// rankCompletionItems sorts items the way editors do: preselected items first,
+// then by sortText, falling back to the label.
+func rankCompletionItems(items []protocol.CompletionItem) {
+	sortKey := func(item protocol.CompletionItem) string {
+		if item.SortText != "" {
+			return item.SortText
+		}
+		return item.Label
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].Preselect != items[j].Preselect {
+			return items[i].Preselect
+		}
+		return sortKey(items[i]) < sortKey(items[j])
+	})
+}
+
+// newCompletionItem converts a protocol completion item.
+func newCompletionItem(item protocol.CompletionItem) CompletionItem {
+	label := item.Label
+	if item.LabelDetails != nil && item.LabelDetails.Detail != "" {
+		label += item.LabelDetails.Detail
+	}
+	result := CompletionItem{
+		Label:      label,
+		Kind:       protocol.TableCompletionItemKindMap[item.Kind],
+		Detail:     strings.TrimSpace(item.Detail),
+		Deprecated: item.Deprecated || slices.Contains(item.Tags, protocol.ComplDeprecated),
+	}
+	if item.Documentation != nil {
+		result.Documentation = renderDocumentation(item.Documentation.Value)
+	}
+	return result
+}
+
+// String formats an item as "label (Kind) detail".
+func (item CompletionItem) String() string {
+	var result strings.Builder
+	result.WriteString(item.Label)
+	if item.Kind != "" {
+		result.WriteString(" (" + item.Kind + ")")
+	}
+	if item.Detail != "" {
+		result.WriteString(" " + item.Detail)
+	}
+	if item.Deprecated {
+		result.WriteString(" [deprecated]")
+	}
+	return result.String()
+}
+
+// insertAt inserts text into content at a 0-based position and returns the new
+// content and the position right after the inserted text.
+func insertAt(content string, position protocol.Position, text string) (string, protocol.Position, error) {
+	lines := strings.Split(content, "\n")
+	if int(position.Line) >= len(lines) {
+		return "", protocol.Position{}, fmt.Errorf("line %d is beyond the end of the file (%d lines)", position.Line+1, len(lines))
+	}
+	lineContent := strings.TrimSuffix(lines[position.Line], "\r")
+	if int(position.Character) > len(lineContent) {
+		return "", protocol.Position{}, fmt.Errorf("column %d is beyond the end of line %d", position.Character+1, position.Line+1)
+	}
+
+	offset := 0
+	for _, l := range lines[:position.Line] {
+		offset += len(l) + 1
+	}
+	offset += int(position.Character)
+
+	end := position
+	if i := strings.LastIndex(text, "\n"); i >= 0 {
+		end.Line += uint32(strings.Count(text, "\n"))
+		end.Character = uint32(len(text) - i - 1)
+	} else {
+		end.Character += uint32(len(text))
+	}
+
+	return content[:offset] + text + content[offset:], end, nil
+}
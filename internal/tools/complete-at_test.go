@@ -0,0 +1,46 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInsertAt(t *testing.T) {
+	content := "func main() {\n\t\n}\n"
+
+	edited, end, err := insertAt(content, protocol.Position{Line: 1, Character: 1}, "client.")
+	require.NoError(t, err)
+	assert.Equal(t, "func main() {\n\tclient.\n}\n", edited)
+	assert.Equal(t, protocol.Position{Line: 1, Character: 8}, end)
+
+	edited, end, err = insertAt(content, protocol.Position{Line: 1, Character: 1}, "x := 1\n\tx.")
+	require.NoError(t, err)
+	assert.Equal(t, "func main() {\n\tx := 1\n\tx.\n}\n", edited)
+	assert.Equal(t, protocol.Position{Line: 2, Character: 3}, end)
+
+	_, _, err = insertAt(content, protocol.Position{Line: 1, Character: 5}, "x")
+	assert.Error(t, err, "column beyond end of line")
+
+	_, _, err = insertAt(content, protocol.Position{Line: 9}, "x")
+	assert.Error(t, err, "line beyond end of file")
+}
+
+func TestRankCompletionItems(t *testing.T) {
+	items := []protocol.CompletionItem{
+		{Label: "zeta"},
+		{Label: "beta", SortText: "b"},
+		{Label: "alpha", SortText: "c"},
+		{Label: "gamma", SortText: "z", Preselect: true},
+	}
+
+	rankCompletionItems(items)
+
+	var labels []string
+	for _, item := range items {
+		labels = append(labels, item.Label)
+	}
+	assert.Equal(t, []string{"gamma", "beta", "alpha", "zeta"}, labels)
+}
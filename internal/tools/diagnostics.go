@@ -6,71 +6,113 @@ import (
 	"log"
 	"os"
 	"strings"
-	"time"
 	"path/filepath" // Added for absolute path conversion
 
 	"github.com/isaacphi/mcp-language-server/internal/lsp"
 	"github.com/isaacphi/mcp-language-server/internal/protocol"
 )
 
+// DiagnosticsResult is the result of get_diagnostics.
+type DiagnosticsResult struct {
+	Path        string       `json:"path"`
+	Diagnostics []Diagnostic `json:"diagnostics"`
+
+	showLineNumbers bool
+}
+
+func (r *DiagnosticsResult) Text() string {
+	if len(r.Diagnostics) == 0 {
+		return "No diagnostics found for " + r.Path
+	}
+
+	// Format the diagnostics
+	var formattedDiagnostics []string
+	for _, diag := range r.Diagnostics {
+		location := fmt.Sprintf("Line %d, Column %d",
+			diag.Location.Line,
+			diag.Location.Column)
+
+		formattedDiag := fmt.Sprintf(
+			"%s\n[%s] %s\n"+
+				"Location: %s\n"+
+				"Message: %s\n",
+			strings.Repeat("=", 60),
+			strings.ToUpper(diag.Severity),
+			r.Path,
+			location,
+			diag.Message)
+
+		if diag.Source != "" {
+			formattedDiag += fmt.Sprintf("Source: %s\n", diag.Source)
+		}
+
+		if diag.Code != "" {
+			formattedDiag += fmt.Sprintf("Code: %s\n", diag.Code)
+		}
+
+		formattedDiag += strings.Repeat("=", 60)
+
+		if diag.Snippet != "" {
+			codeContext := diag.Snippet
+			if r.showLineNumbers {
+				startLine := diag.Location.Line
+				if diag.SnippetLine != 0 {
+					startLine = diag.SnippetLine
+				}
+				codeContext = addLineNumbers(codeContext, startLine)
+			}
+			formattedDiag += fmt.Sprintf("\n%s\n", codeContext)
+		}
+
+		formattedDiagnostics = append(formattedDiagnostics, formattedDiag)
+	}
+
+	return strings.Join(formattedDiagnostics, "\n")
+}
+
 // GetDiagnostics retrieves diagnostics for a specific file from the language server
-func GetDiagnosticsForFile(ctx context.Context, client *lsp.Client, filePath string, includeContext bool, showLineNumbers bool) (string, error) {
+func GetDiagnosticsForFile(ctx context.Context, client *lsp.Client, filePath string, includeContext bool, showLineNumbers bool) (*DiagnosticsResult, error) {
 	// Ensure filePath is absolute
 	absFilePath, err := filepath.Abs(filePath)
 	if err != nil {
-		return "", fmt.Errorf("could not get absolute path for '%s': %w", filePath, err)
+		return nil, fmt.Errorf("could not get absolute path for '%s': %w", filePath, err)
 	}
 	filePath = absFilePath // Use absolute path from now on
 
 	err = client.OpenFile(ctx, filePath) // Use absolute path
 	if err != nil {
-		return "", fmt.Errorf("could not open file '%s': %w", filePath, err)
+		return nil, fmt.Errorf("could not open file '%s': %w", filePath, err)
 	}
 
-	// Wait for diagnostics
-	// TODO: wait for notification
-	time.Sleep(time.Second * 3)
-
 	// Convert the file path to URI format
 	uri := protocol.DocumentUri("file://" + filePath)
 
-	// Request fresh diagnostics
-	diagParams := protocol.DocumentDiagnosticParams{
-		TextDocument: protocol.TextDocumentIdentifier{URI: uri},
-	}
-	_, err = client.Diagnostic(ctx, diagParams)
+	// Wait for the server to publish diagnostics for the current version of the file
+	diagnostics, err := client.WaitForDiagnostics(ctx, filePath)
 	if err != nil {
-		log.Printf("failed to get diagnostics: %v", err)
+		return nil, fmt.Errorf("failed to get diagnostics: %w", err)
 	}
 
-	// Get diagnostics from the cache
-	diagnostics := client.GetFileDiagnostics(uri)
-
-	if len(diagnostics) == 0 {
-		return "No diagnostics found for " + filePath, nil
+	result := &DiagnosticsResult{
+		Path:            filePath,
+		Diagnostics:     newDiagnostics(uri, diagnostics),
+		showLineNumbers: showLineNumbers,
 	}
 
-	// Format the diagnostics
-	var formattedDiagnostics []string
-	for _, diag := range diagnostics {
-		severity := getSeverityString(diag.Severity)
-		location := fmt.Sprintf("Line %d, Column %d",
-			diag.Range.Start.Line+1,
-			diag.Range.Start.Character+1)
-
+	for i, diag := range diagnostics {
 		// Get the file content for context if needed
-		var codeContext string
-		startLine := diag.Range.Start.Line + 1
 		if includeContext {
 			content, loc, err := GetFullDefinition(ctx, client, protocol.Location{
 				URI:   uri,
 				Range: diag.Range,
 			})
-			startLine = loc.Range.Start.Line + 1
 			if err != nil {
 				log.Printf("failed to get file content: %v", err)
 			} else {
-				codeContext = content
+				result.Diagnostics[i].Snippet = content
+				if loc.Range.Start.Line != diag.Range.Start.Line {
+					result.Diagnostics[i].SnippetLine = int(loc.Range.Start.Line) + 1
+				}
 			}
 		} else {
 			// Read just the line with the error
@@ -78,42 +120,13 @@ func GetDiagnosticsForFile(ctx context.Context, client *lsp.Client, filePath str
 			if err == nil {
 				lines := strings.Split(string(content), "\n")
 				if int(diag.Range.Start.Line) < len(lines) {
-					codeContext = lines[diag.Range.Start.Line]
+					result.Diagnostics[i].Snippet = lines[diag.Range.Start.Line]
 				}
 			}
 		}
-
-		formattedDiag := fmt.Sprintf(
-			"%s\n[%s] %s\n"+
-				"Location: %s\n"+
-				"Message: %s\n",
-			strings.Repeat("=", 60),
-			severity,
-			filePath,
-			location,
-			diag.Message)
-
-		if diag.Source != "" {
-			formattedDiag += fmt.Sprintf("Source: %s\n", diag.Source)
-		}
-
-		if diag.Code != nil {
-			formattedDiag += fmt.Sprintf("Code: %v\n", diag.Code)
-		}
-
-		formattedDiag += strings.Repeat("=", 60)
-
-		if codeContext != "" {
-			if showLineNumbers {
-				codeContext = addLineNumbers(codeContext, int(startLine))
-			}
-			formattedDiag += fmt.Sprintf("\n%s\n", codeContext)
-		}
-
-		formattedDiagnostics = append(formattedDiagnostics, formattedDiag)
 	}
 
-	return strings.Join(formattedDiagnostics, "\n"), nil
+	return result, nil
 }
 
 func getSeverityString(severity protocol.DiagnosticSeverity) string {
@@ -0,0 +1,146 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// DocumentLinksResult is the result of document_links.
+type DocumentLinksResult struct {
+	Path  string         `json:"path"`
+	Links []DocumentLink `json:"links"`
+}
+
+// DocumentLink is a link in a document and where it points to.
+type DocumentLink struct {
+	Location Location `json:"location"`
+	// Text is the linked text in the document
+	Text string `json:"text"`
+	// Target is a path or URL, empty if the server could not resolve it
+	Target  string `json:"target,omitempty"`
+	Tooltip string `json:"tooltip,omitempty"`
+}
+
+func (r *DocumentLinksResult) Text() string {
+	if len(r.Links) == 0 {
+		return fmt.Sprintf("No links found in %s", r.Path)
+	}
+
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("Links in %s: %d\n", r.Path, len(r.Links)))
+	output.WriteString(strings.Repeat("=", 80) + "\n")
+
+	for _, link := range r.Links {
+		target := link.Target
+		if target == "" {
+			target = "(unresolved)"
+		}
+		output.WriteString(fmt.Sprintf("%d:%d %s -> %s\n",
+			link.Location.Line,
+			link.Location.Column,
+			link.Text,
+			target))
+		if link.Tooltip != "" {
+			output.WriteString(fmt.Sprintf("    %s\n", link.Tooltip))
+		}
+	}
+
+	return output.String()
+}
+
+// GetDocumentLinks lists every link the language server recognises in a file,
+// such as import paths, URLs in comments and #include targets, with the text of
+// the link and its target. Links without a target are resolved if the server
+// supports it.
+func GetDocumentLinks(ctx context.Context, client *lsp.Client, filePath string) (*DocumentLinksResult, error) {
+	absFilePath, err := filepath.Abs(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("could not get absolute path for '%s': %w", filePath, err)
+	}
+	filePath = absFilePath
+
+	err = client.OpenFile(ctx, filePath)
+	if err != nil {
+		return nil, fmt.Errorf("could not open file '%s': %w", filePath, err)
+	}
+
+	uri := protocol.DocumentUri("file://" + filePath)
+	links, err := client.DocumentLink(ctx, protocol.DocumentLinkParams{
+		TextDocument: protocol.TextDocumentIdentifier{URI: uri},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get document links: %w", err)
+	}
+
+	result := &DocumentLinksResult{Path: filePath, Links: []DocumentLink{}}
+	if len(links) == 0 {
+		return result, nil
+	}
+
+	canResolve := false
+	if options := client.ServerCapabilities().DocumentLinkProvider; options != nil {
+		canResolve = options.ResolveProvider
+	}
+
+	sort.SliceStable(links, func(i, j int) bool {
+		a, b := links[i].Range.Start, links[j].Range.Start
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Character < b.Character
+	})
+
+	for _, link := range links {
+		if link.Target == nil && canResolve {
+			resolved, err := client.ResolveDocumentLink(ctx, link)
+			if err != nil {
+				log.Printf("Error resolving document link at %d:%d: %v\n", link.Range.Start.Line+1, link.Range.Start.Character+1, err)
+			} else {
+				link = resolved
+			}
+		}
+
+		text, err := ExtractTextFromLocation(protocol.Location{URI: uri, Range: link.Range})
+		if err != nil {
+			text = "?"
+		}
+
+		target := ""
+		if link.Target != nil {
+			target = formatLinkTarget(string(*link.Target))
+		}
+
+		result.Links = append(result.Links, DocumentLink{
+			Location: newLocation(uri, link.Range),
+			Text:     text,
+			Target:   target,
+			Tooltip:  link.Tooltip,
+		})
+	}
+
+	return result, nil
+}
+
+// formatLinkTarget returns the path of file targets, keeping any line fragment
+// such as #L10, and other targets such as web URLs unchanged.
+func formatLinkTarget(target string) string {
+	if !strings.HasPrefix(target, "file://") {
+		return target
+	}
+	path, fragment, _ := strings.Cut(strings.TrimPrefix(target, "file://"), "#")
+	if unescaped, err := url.PathUnescape(path); err == nil {
+		path = unescaped
+	}
+	if fragment != "" {
+		return path + "#" + fragment
+	}
+	return path
+}
@@ -0,0 +1,76 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatLinkTarget(t *testing.T) {
+	tests := []struct {
+		target string
+		want   string
+	}{
+		{"file:///ws/README.md", "/ws/README.md"},
+		{"file:///ws/my%20docs/guide.md#L10", "/ws/my docs/guide.md#L10"},
+		{"file:///ws/bad%zz.md", "/ws/bad%zz.md"},
+		{"https://pkg.go.dev/fmt", "https://pkg.go.dev/fmt"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, formatLinkTarget(tt.target), tt.target)
+	}
+}
+
+func TestGetDocumentLinks(t *testing.T) {
+	path := writeTestFile(t, "main.go", "package main\n\nimport \"fmt\"\n\n// See https://example.com\n")
+	fmtTarget := "https://pkg.go.dev/fmt"
+	webTarget := "https://example.com"
+	client, server := newFakeClient(t, protocol.ServerCapabilities{
+		DocumentLinkProvider: &protocol.DocumentLinkOptions{ResolveProvider: true},
+	}, map[string]fakeHandler{
+		// Out of order, with the import left for documentLink/resolve
+		"textDocument/documentLink": func(json.RawMessage) (any, error) {
+			return []protocol.DocumentLink{
+				{Range: lineRange(4, 7, 4, 26), Target: &webTarget, Tooltip: "Open in browser"},
+				{Range: lineRange(2, 8, 2, 11)},
+			}, nil
+		},
+		"documentLink/resolve": func(raw json.RawMessage) (any, error) {
+			var link protocol.DocumentLink
+			if err := json.Unmarshal(raw, &link); err != nil {
+				return nil, err
+			}
+			link.Target = &fmtTarget
+			return link, nil
+		},
+	})
+
+	result, err := GetDocumentLinks(context.Background(), client, path)
+	require.NoError(t, err)
+	assert.Equal(t, []DocumentLink{
+		{Location: Location{Path: path, Line: 3, Column: 9, EndLine: 3, EndColumn: 12}, Text: "fmt", Target: fmtTarget},
+		{Location: Location{Path: path, Line: 5, Column: 8, EndLine: 5, EndColumn: 27}, Text: "https://example.com", Target: "https://example.com", Tooltip: "Open in browser"},
+	}, result.Links)
+	assert.Equal(t, 1, server.requestCount("documentLink/resolve"))
+
+	assert.Equal(t, "Links in "+path+": 2\n"+
+		"================================================================================\n"+
+		"3:9 fmt -> https://pkg.go.dev/fmt\n"+
+		"5:8 https://example.com -> https://example.com\n"+
+		"    Open in browser\n", result.Text())
+}
+
+func TestDocumentLinksResultTextUnresolved(t *testing.T) {
+	result := &DocumentLinksResult{
+		Path:  "/ws/main.go",
+		Links: []DocumentLink{{Location: Location{Path: "/ws/main.go", Line: 3, Column: 9}, Text: "fmt"}},
+	}
+	assert.Contains(t, result.Text(), "3:9 fmt -> (unresolved)\n")
+
+	empty := &DocumentLinksResult{Path: "/ws/main.go"}
+	assert.Equal(t, "No links found in /ws/main.go", empty.Text())
+}
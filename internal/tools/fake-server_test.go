@@ -0,0 +1,113 @@
+package tools
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeHandler answers a request to the fake server. A *lsp.ResponseError is
+// sent back as the error of the response.
+type fakeHandler func(params json.RawMessage) (any, error)
+
+// fakeServer is an in-process language server for tests. It answers requests
+// with the handler registered for their method and with null otherwise.
+type fakeServer struct {
+	capabilities protocol.ServerCapabilities
+	handlers     map[string]fakeHandler
+
+	mu       sync.Mutex
+	requests map[string]int
+}
+
+// newFakeClient starts a fake server with the given capabilities and handlers
+// and returns an initialized client connected to it.
+func newFakeClient(t *testing.T, capabilities protocol.ServerCapabilities, handlers map[string]fakeHandler) (*lsp.Client, *fakeServer) {
+	t.Helper()
+
+	serverIn, clientOut := io.Pipe()
+	clientIn, serverOut := io.Pipe()
+	server := &fakeServer{
+		capabilities: capabilities,
+		handlers:     handlers,
+		requests:     make(map[string]int),
+	}
+	go server.serve(bufio.NewReader(serverIn), serverOut)
+
+	client := lsp.NewStreamClient(clientIn, clientOut)
+	t.Cleanup(func() {
+		clientOut.Close()
+		serverOut.Close()
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err := client.InitializeLSPClient(ctx, t.TempDir())
+	require.NoError(t, err)
+	return client, server
+}
+
+func (s *fakeServer) serve(in *bufio.Reader, out io.Writer) {
+	for {
+		msg, err := lsp.ReadMessage(in)
+		if err != nil {
+			return
+		}
+
+		// Notifications and responses to requests of the client need no answer
+		if msg.ID == 0 || msg.Method == "" {
+			continue
+		}
+		s.mu.Lock()
+		s.requests[msg.Method]++
+		s.mu.Unlock()
+
+		var result any
+		var handlerErr error
+		if msg.Method == "initialize" {
+			result = protocol.InitializeResult{Capabilities: s.capabilities}
+		} else if handler, ok := s.handlers[msg.Method]; ok {
+			result, handlerErr = handler(msg.Params)
+		}
+
+		response := &lsp.Message{JSONRPC: "2.0", ID: msg.ID}
+		if handlerErr != nil {
+			responseErr, ok := handlerErr.(*lsp.ResponseError)
+			if !ok {
+				responseErr = &lsp.ResponseError{Code: -32603, Message: handlerErr.Error()}
+			}
+			response.Error = responseErr
+		} else {
+			response.Result, _ = json.Marshal(result)
+		}
+		if err := lsp.WriteMessage(out, response); err != nil {
+			return
+		}
+	}
+}
+
+// requestCount returns how often method was requested.
+func (s *fakeServer) requestCount(method string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests[method]
+}
+
+// writeTestFile writes content to name in a temporary directory and returns
+// its path.
+func writeTestFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
@@ -0,0 +1,59 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// ImplementationsResult is the result of find_implementations.
+type ImplementationsResult struct {
+	// Symbol is the name the implementations were looked up by, if any
+	Symbol          string       `json:"symbol,omitempty"`
+	Position        Location     `json:"position"`
+	Implementations []Definition `json:"implementations"`
+
+	target          string
+	showLineNumbers bool
+}
+
+func (r *ImplementationsResult) Text() string {
+	if len(r.Implementations) == 0 {
+		return fmt.Sprintf("No implementations found for %s", r.target)
+	}
+
+	header := fmt.Sprintf("Found %d implementation(s) of %s\n", len(r.Implementations), r.target)
+	return header + strings.Join(formatDefinitions(r.Implementations, r.showLineNumbers), "\n")
+}
+
+// FindImplementations returns the full code of every implementation of the
+// interface, abstract method or type at the given position or with the given name.
+func FindImplementations(ctx context.Context, client *lsp.Client, filePath string, line, column int, symbolName string, showLineNumbers bool) (*ImplementationsResult, error) {
+	position, err := resolvePosition(ctx, client, filePath, line, column, symbolName)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := client.Implementation(ctx, protocol.ImplementationParams{
+		TextDocumentPositionParams: position,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get implementations: %w", err)
+	}
+
+	locations, err := result.Locations()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse implementations: %w", err)
+	}
+
+	return &ImplementationsResult{
+		Symbol:          symbolName,
+		Position:        newPosition(position.TextDocument.URI, position.Position),
+		Implementations: locationDefinitions(ctx, client, locations),
+		target:          describePosition(position, symbolName),
+		showLineNumbers: showLineNumbers,
+	}, nil
+}
@@ -0,0 +1,141 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const shapesSource = `package shapes
+
+type Shape interface {
+	Area() float64
+}
+
+type Square struct{ side float64 }
+
+func (s Square) Area() float64 {
+	return s.side * s.side
+}
+`
+
+func lineRange(startLine, startChar, endLine, endChar uint32) protocol.Range {
+	return protocol.Range{
+		Start: protocol.Position{Line: startLine, Character: startChar},
+		End:   protocol.Position{Line: endLine, Character: endChar},
+	}
+}
+
+// newShapesClient returns a client for a server that answers implementation
+// and typeDefinition requests on shapesSource with the given raw results.
+func newShapesClient(t *testing.T, implementation, typeDefinition string) *lsp.Client {
+	client, _ := newFakeClient(t, protocol.ServerCapabilities{}, map[string]fakeHandler{
+		"textDocument/documentSymbol": func(json.RawMessage) (any, error) {
+			return []protocol.DocumentSymbol{
+				{Name: "Shape", Kind: protocol.Interface, Range: lineRange(2, 0, 4, 1), SelectionRange: lineRange(2, 5, 2, 10)},
+				{Name: "Square", Kind: protocol.Struct, Range: lineRange(6, 0, 6, 34), SelectionRange: lineRange(6, 5, 6, 11)},
+				{Name: "Area", Kind: protocol.Method, Range: lineRange(8, 0, 10, 1), SelectionRange: lineRange(8, 16, 8, 20)},
+			}, nil
+		},
+		"textDocument/implementation": func(json.RawMessage) (any, error) {
+			return json.RawMessage(implementation), nil
+		},
+		"textDocument/typeDefinition": func(json.RawMessage) (any, error) {
+			return json.RawMessage(typeDefinition), nil
+		},
+	})
+	return client
+}
+
+func TestFindImplementations(t *testing.T) {
+	path := writeTestFile(t, "shapes.go", shapesSource)
+	uri := "file://" + path
+	areaLink := `{"targetUri":"` + uri + `","targetRange":{"start":{"line":8,"character":0},"end":{"line":10,"character":1}},"targetSelectionRange":{"start":{"line":8,"character":16},"end":{"line":8,"character":20}}}`
+	areaLocation := `{"uri":"` + uri + `","range":{"start":{"line":8,"character":16},"end":{"line":8,"character":20}}}`
+
+	tests := []struct {
+		name     string
+		response string
+		want     []Definition
+	}{
+		{
+			name:     "location links",
+			response: "[" + areaLink + "," + areaLink + "]",
+			want: []Definition{{
+				Location: Location{Path: path, Line: 9, Column: 1, EndLine: 11, EndColumn: 2},
+				Snippet:  "func (s Square) Area() float64 {\n\treturn s.side * s.side\n}",
+			}},
+		},
+		{
+			name:     "single location",
+			response: areaLocation,
+			want: []Definition{{
+				Location: Location{Path: path, Line: 9, Column: 1, EndLine: 11, EndColumn: 2},
+				Snippet:  "func (s Square) Area() float64 {\n\treturn s.side * s.side\n}",
+			}},
+		},
+		{
+			name:     "none",
+			response: "null",
+			want:     []Definition{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newShapesClient(t, tt.response, "null")
+
+			result, err := FindImplementations(context.Background(), client, path, 4, 2, "", false)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, result.Implementations)
+			assert.Equal(t, Location{Path: path, Line: 4, Column: 2}, result.Position)
+		})
+	}
+}
+
+func TestImplementationsResultText(t *testing.T) {
+	result := &ImplementationsResult{
+		Implementations: []Definition{{
+			Location: Location{Path: "/ws/shapes.go", Line: 9, Column: 1, EndLine: 11, EndColumn: 2},
+			Snippet:  "func (s Square) Area() float64 {\n\treturn s.side * s.side\n}",
+		}},
+		target:          "Area (/ws/shapes.go:4:2)",
+		showLineNumbers: true,
+	}
+	assert.Equal(t, "Found 1 implementation(s) of Area (/ws/shapes.go:4:2)\n"+
+		"================================================================================\n"+
+		"File: /ws/shapes.go\n"+
+		"Start Position: Line 9, Column 1\n"+
+		"End Position: Line 11, Column 2\n"+
+		"================================================================================\n"+
+		" 9|func (s Square) Area() float64 {\n"+
+		"10|\treturn s.side * s.side\n"+
+		"11|}\n\n", result.Text())
+
+	empty := &ImplementationsResult{target: "/ws/shapes.go:4:2"}
+	assert.Equal(t, "No implementations found for /ws/shapes.go:4:2", empty.Text())
+}
+
+func TestReadTypeDefinition(t *testing.T) {
+	path := writeTestFile(t, "shapes.go", shapesSource)
+	uri := "file://" + path
+	squareLocation := `[{"uri":"` + uri + `","range":{"start":{"line":6,"character":5},"end":{"line":6,"character":11}}}]`
+
+	client := newShapesClient(t, "null", squareLocation)
+	result, err := ReadTypeDefinition(context.Background(), client, path, 9, 7, "", false)
+	require.NoError(t, err)
+	assert.Equal(t, []Definition{{
+		Location: Location{Path: path, Line: 7, Column: 1, EndLine: 7, EndColumn: 35},
+		Snippet:  "type Square struct{ side float64 }",
+	}}, result.Definitions)
+
+	client = newShapesClient(t, "null", "null")
+	result, err = ReadTypeDefinition(context.Background(), client, path, 9, 7, "", false)
+	require.NoError(t, err)
+	assert.Empty(t, result.Definitions)
+	assert.Equal(t, "No type definition found for "+path+":9:7", result.Text())
+}
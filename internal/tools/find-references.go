@@ -9,21 +9,97 @@ import (
 	"github.com/isaacphi/mcp-language-server/internal/protocol"
 )
 
-func FindReferences(ctx context.Context, client *lsp.Client, symbolName string, showLineNumbers bool) (string, error) {
+// Reference is a use of a symbol with the code around it.
+type Reference struct {
+	Location Location `json:"location"`
+	Snippet  string   `json:"snippet,omitempty"`
+	// SnippetLine is the 1-based line Snippet starts on
+	SnippetLine int `json:"snippetLine,omitempty"`
+	// Language is the language of the server that found the reference
+	Language string `json:"language,omitempty"`
+}
+
+// ReferencesResult is the result of find_references.
+type ReferencesResult struct {
+	Symbol     string      `json:"symbol"`
+	References []Reference `json:"references"`
+
+	showLineNumbers bool
+	showLanguage    bool
+}
+
+func (r *ReferencesResult) Text() string {
+	if len(r.References) == 0 {
+		banner := strings.Repeat("=", 80) + "\n"
+		return fmt.Sprintf("%sNo references found for symbol: %s\n%s",
+			banner, r.Symbol, banner)
+	}
+
+	// Group references by file
+	var paths []string
+	refsByFile := make(map[string][]Reference)
+	for _, ref := range r.References {
+		if _, ok := refsByFile[ref.Location.Path]; !ok {
+			paths = append(paths, ref.Location.Path)
+		}
+		refsByFile[ref.Location.Path] = append(refsByFile[ref.Location.Path], ref)
+	}
+
+	var allReferences []string
+	for _, path := range paths {
+		fileRefs := refsByFile[path]
+		language := ""
+		if r.showLanguage {
+			language = fmt.Sprintf("Language: %s\n", fileRefs[0].Language)
+		}
+		// Format file header similarly to ReadDefinition style
+		fileInfo := fmt.Sprintf("\n%s\nFile: %s\n%sReferences in File: %d\n%s\n",
+			strings.Repeat("=", 60),
+			path,
+			language,
+			len(fileRefs),
+			strings.Repeat("=", 60))
+		allReferences = append(allReferences, fileInfo)
+
+		for _, ref := range fileRefs {
+			snippet := ref.Snippet
+			if r.showLineNumbers {
+				snippet = addLineNumbers(snippet, ref.SnippetLine)
+			}
+
+			// Format reference location info
+			refInfo := fmt.Sprintf("Reference at Line %d, Column %d:\n%s\n%s\n",
+				ref.Location.Line,
+				ref.Location.Column,
+				strings.Repeat("-", 40),
+				snippet)
+
+			allReferences = append(allReferences, refInfo)
+		}
+	}
+
+	return strings.Join(allReferences, "\n")
+}
+
+func FindReferences(ctx context.Context, client *lsp.Client, symbolName string, showLineNumbers bool) (*ReferencesResult, error) {
 	// First get the symbol location like ReadDefinition does
 	symbolResult, err := client.Symbol(ctx, protocol.WorkspaceSymbolParams{
 		Query: symbolName,
 	})
 	if err != nil {
-		return "", fmt.Errorf("Failed to fetch symbol: %v", err)
+		return nil, fmt.Errorf("Failed to fetch symbol: %v", err)
 	}
 
 	results, err := symbolResult.Results()
 	if err != nil {
-		return "", fmt.Errorf("Failed to parse results: %v", err)
+		return nil, fmt.Errorf("Failed to parse results: %v", err)
 	}
 
-	var allReferences []string
+	result := &ReferencesResult{
+		Symbol:          symbolName,
+		References:      []Reference{},
+		showLineNumbers: showLineNumbers,
+	}
 	for _, symbol := range results {
 		if symbol.GetName() != symbolName {
 			continue
@@ -47,53 +123,59 @@ func FindReferences(ctx context.Context, client *lsp.Client, symbolName string,
 
 		refs, err := client.References(ctx, refsParams)
 		if err != nil {
-			return "", fmt.Errorf("Failed to get references: %v", err)
+			return nil, fmt.Errorf("Failed to get references: %v", err)
 		}
 
-		// Group references by file
-		refsByFile := make(map[protocol.DocumentUri][]protocol.Location)
 		for _, ref := range refs {
-			refsByFile[ref.URI] = append(refsByFile[ref.URI], ref)
+			// Use GetFullDefinition but with a smaller context window
+			snippet, snippetLoc, err := GetFullDefinition(ctx, client, ref)
+			if err != nil {
+				continue
+			}
+
+			result.References = append(result.References, Reference{
+				Location:    newLocation(ref.URI, ref.Range),
+				Snippet:     snippet,
+				SnippetLine: int(snippetLoc.Range.Start.Line) + 1,
+			})
 		}
+	}
 
-		// Process each file's references
-		for uri, fileRefs := range refsByFile {
-			// Format file header similarly to ReadDefinition style
-			fileInfo := fmt.Sprintf("\n%s\nFile: %s\nReferences in File: %d\n%s\n",
-				strings.Repeat("=", 60),
-				strings.TrimPrefix(string(uri), "file://"),
-				len(fileRefs),
-				strings.Repeat("=", 60))
-			allReferences = append(allReferences, fileInfo)
-
-			for _, ref := range fileRefs {
-				// Use GetFullDefinition but with a smaller context window
-				snippet, _, err := GetFullDefinition(ctx, client, ref)
-				if err != nil {
-					continue
-				}
-
-				if showLineNumbers {
-					snippet = addLineNumbers(snippet, int(ref.Range.Start.Line)+1)
-				}
-
-				// Format reference location info
-				refInfo := fmt.Sprintf("Reference at Line %d, Column %d:\n%s\n%s\n",
-					ref.Range.Start.Line+1,
-					ref.Range.Start.Character+1,
-					strings.Repeat("-", 40),
-					snippet)
-
-				allReferences = append(allReferences, refInfo)
-			}
+	return result, nil
+}
+
+// FindReferencesInLanguages finds the references to a symbol with each of the
+// given language servers concurrently and merges them, tagging every reference
+// with the language of the server that found it.
+func FindReferencesInLanguages(ctx context.Context, clients map[string]*lsp.Client, symbolName string, showLineNumbers bool) (*ReferencesResult, error) {
+	byLanguage, err := queryLanguages(ctx, clients, func(ctx context.Context, client *lsp.Client) ([]Reference, error) {
+		result, err := FindReferences(ctx, client, symbolName, showLineNumbers)
+		if err != nil {
+			return nil, err
 		}
+		return result.References, nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	if len(allReferences) == 0 {
-		banner := strings.Repeat("=", 80) + "\n"
-		return fmt.Sprintf("%sNo references found for symbol: %s\n%s",
-			banner, symbolName, banner), nil
+	result := &ReferencesResult{
+		Symbol:          symbolName,
+		References:      []Reference{},
+		showLineNumbers: showLineNumbers,
+		showLanguage:    len(clients) > 1,
+	}
+	seen := make(map[Location]bool)
+	for _, language := range sortedLanguages(byLanguage) {
+		for _, ref := range byLanguage[language] {
+			if seen[ref.Location] {
+				continue
+			}
+			seen[ref.Location] = true
+			ref.Language = language
+			result.References = append(result.References, ref)
+		}
 	}
 
-	return strings.Join(allReferences, "\n"), nil
+	return result, nil
 }
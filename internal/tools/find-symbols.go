@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/isaacphi/mcp-language-server/internal/lsp"
@@ -13,7 +14,9 @@ import (
 
 // FindSymbolsTool defines the MCP tool for finding symbols using LSP.
 type FindSymbolsTool struct {
-	Client *lsp.Client // Assuming Client is accessible or passed appropriately
+	Client   *lsp.Client            // Document scope: the language server of the file
+	Language string                 // Document scope: the language of Client
+	Clients  map[string]*lsp.Client // Workspace scope: every language server, by language
 }
 
 // FindSymbolsArgs defines the arguments for the find_symbols tool.
@@ -22,6 +25,9 @@ type FindSymbolsArgs struct {
 	Scope           string `json:"scope"`                      // Required: "workspace" or "document".
 	FilePath        string `json:"filePath,omitempty"`         // Optional: Required if scope is "document".
 	ShowLineNumbers bool   `json:"showLineNumbers,omitempty"` // Optional: Default true.
+	Format          string `json:"format,omitempty"`          // Optional: "text" (default) or "json".
+	Language        string `json:"language,omitempty"`        // Optional: Only search the language server of this language (workspace scope).
+	Kind            string `json:"kind,omitempty"`            // Optional: Only return symbols of this kind, e.g. "Function".
 }
 
 // FindSymbolsResult defines the result structure.
@@ -30,6 +36,12 @@ type FindSymbolsResult struct {
 	Symbols []string `json:"symbols"`
 }
 
+// FindSymbolsJSONResult is the result in the json output format, with
+// structured symbols instead of formatted strings.
+type FindSymbolsJSONResult struct {
+	Symbols []Symbol `json:"symbols"`
+}
+
 // Name returns the name of the tool.
 func (t *FindSymbolsTool) Name() string {
 	return "find_symbols"
@@ -48,7 +60,10 @@ func (t *FindSymbolsTool) Schema() string {
 			"query": {"type": "string", "description": "Search query string."},
 			"scope": {"type": "string", "enum": ["workspace", "document"], "description": "Search scope ('workspace' or 'document')."},
 			"filePath": {"type": "string", "description": "Path to the file (required if scope is 'document')."},
-			"showLineNumbers": {"type": "boolean", "default": true, "description": "Include line numbers in the result."}
+			"showLineNumbers": {"type": "boolean", "default": true, "description": "Include line numbers in the result."},
+			"format": {"type": "string", "enum": ["text", "json"], "default": "text", "description": "Return symbols as formatted strings ('text') or as objects with name, kind, container, language and location ('json')."},
+			"language": {"type": "string", "description": "Only search the language server of this language (workspace scope). Defaults to all language servers."},
+			"kind": {"type": "string", "description": "Only return symbols of this kind, e.g. 'Function', 'Class' or 'Method'."}
 		},
 		"required": ["query", "scope"]
 	}`
@@ -57,8 +72,8 @@ func (t *FindSymbolsTool) Schema() string {
 		"properties": {
 			"symbols": {
 				"type": "array",
-				"items": {"type": "string"},
-				"description": "List of found symbols with their locations."
+				"items": {"type": ["string", "object"]},
+				"description": "List of found symbols with their locations, best matches first. Workspace results are tagged with the language of the server that found them. Strings in the text format, objects in the json format."
 			}
 		}
 	}`
@@ -87,19 +102,28 @@ func (t *FindSymbolsTool) Execute(ctx context.Context, argsJSON json.RawMessage)
 	}
 
 
+	if err := ValidateFormat(args.Format); err != nil {
+		return nil, err
+	}
+
 	var symbolsResult any // Use any instead of interface{}
+	var uri protocol.DocumentUri // Document scope only
 	var err error
 
 	switch args.Scope {
 	case "workspace":
-		// Workspace scope search
-		params := protocol.WorkspaceSymbolParams{
-			Query: args.Query,
+		// Workspace scope search, fanned out to every language server
+		clients := t.Clients
+		if args.Language != "" {
+			client, ok := t.Clients[args.Language]
+			if !ok {
+				return nil, fmt.Errorf("LSP client for language '%s' not found or not initialized", args.Language)
+			}
+			clients = map[string]*lsp.Client{args.Language: client}
 		}
-		// Note: RequestWorkspaceSymbols returns []protocol.SymbolInformation
-		symbolsResult, err = t.Client.RequestWorkspaceSymbols(ctx, params)
+		symbolsResult, err = searchWorkspaceSymbols(ctx, clients, args.Query, args.Kind)
 		if err != nil {
-			return nil, fmt.Errorf("LSP workspace/symbol request failed: %w", err)
+			return nil, err
 		}
 
 	case "document":
@@ -120,9 +144,10 @@ func (t *FindSymbolsTool) Execute(ctx context.Context, argsJSON json.RawMessage)
 			}
 		}
 
+		uri = protocol.DocumentUri("file://" + absPath)
 		params := protocol.DocumentSymbolParams{
 			TextDocument: protocol.TextDocumentIdentifier{
-				URI: protocol.DocumentUri("file://" + absPath),
+				URI: uri,
 			},
 		}
 		// Note: RequestDocumentSymbols returns interface{} which can be []protocol.DocumentSymbol or []protocol.SymbolInformation
@@ -130,15 +155,22 @@ func (t *FindSymbolsTool) Execute(ctx context.Context, argsJSON json.RawMessage)
 		if err != nil {
 			return nil, fmt.Errorf("LSP textDocument/documentSymbol request failed: %w", err)
 		}
+		if args.Kind != "" {
+			symbolsResult = filterSymbolsByKind(symbolsResult, args.Kind)
+		}
 
 	default:
 		return nil, fmt.Errorf("invalid scope: %s. Must be 'workspace' or 'document'", args.Scope)
 	}
 
-	// Format the result
-	formattedSymbols := formatSymbols(symbolsResult, args.ShowLineNumbers)
+	var result any
+	if args.Format == FormatJSON {
+		result = FindSymbolsJSONResult{Symbols: collectSymbols(symbolsResult, uri, t.Language)}
+	} else {
+		// Format the result
+		result = FindSymbolsResult{Symbols: formatSymbols(symbolsResult, args.ShowLineNumbers)}
+	}
 
-	result := FindSymbolsResult{Symbols: formattedSymbols}
 	resultJSON, err := json.Marshal(result)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal find_symbols result: %w", err)
@@ -147,6 +179,132 @@ func (t *FindSymbolsTool) Execute(ctx context.Context, argsJSON json.RawMessage)
 	return resultJSON, nil
 }
 
+// languageSymbol is a workspace symbol tagged with the language of the server
+// that found it.
+type languageSymbol struct {
+	language string
+	symbol   protocol.SymbolInformation
+}
+
+// searchWorkspaceSymbols sends the workspace/symbol query to every client
+// concurrently and merges the results. A failing server is skipped as long as
+// another one answers.
+func searchWorkspaceSymbols(ctx context.Context, clients map[string]*lsp.Client, query, kind string) ([]languageSymbol, error) {
+	byLanguage, err := queryLanguages(ctx, clients, func(ctx context.Context, client *lsp.Client) ([]protocol.SymbolInformation, error) {
+		return client.RequestWorkspaceSymbols(ctx, protocol.WorkspaceSymbolParams{
+			Query: query,
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("LSP workspace/symbol request failed: %w", err)
+	}
+
+	return mergeWorkspaceSymbols(byLanguage, query, kind), nil
+}
+
+// mergeWorkspaceSymbols combines the symbols found by each language server,
+// keeping only those of the given kind if one is set. A symbol reported by
+// several servers is kept once, for the first language in alphabetical order.
+// The result is ranked by how well the names match the query.
+func mergeWorkspaceSymbols(byLanguage map[string][]protocol.SymbolInformation, query, kind string) []languageSymbol {
+	merged := []languageSymbol{}
+	seen := make(map[string]bool)
+	for _, language := range sortedLanguages(byLanguage) {
+		for _, symbol := range byLanguage[language] {
+			if kind != "" && !strings.EqualFold(symbolKindToString(symbol.Kind), kind) {
+				continue
+			}
+			start := symbol.Location.Range.Start
+			key := fmt.Sprintf("%s:%d:%d:%s", symbol.Location.URI, start.Line, start.Character, symbol.Name)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			merged = append(merged, languageSymbol{language: language, symbol: symbol})
+		}
+	}
+
+	sort.SliceStable(merged, func(i, j int) bool {
+		a, b := merged[i].symbol, merged[j].symbol
+		if sa, sb := symbolMatchScore(a, query), symbolMatchScore(b, query); sa != sb {
+			return sa < sb
+		}
+		if a.Location.URI != b.Location.URI {
+			return a.Location.URI < b.Location.URI
+		}
+		return a.Location.Range.Start.Line < b.Location.Range.Start.Line
+	})
+	return merged
+}
+
+// symbolMatchScore rates how well a symbol matches a query, lower is better:
+// exact matches first, then prefix and substring matches, case-sensitive before
+// case-insensitive, then whatever else the server considered a fuzzy match.
+// Queries containing a dot, such as "Client.Call", are also compared with the
+// name qualified by its container.
+func symbolMatchScore(symbol protocol.SymbolInformation, query string) int {
+	score := nameMatchScore(symbol.Name, query)
+	if strings.Contains(query, ".") && symbol.ContainerName != "" {
+		score = min(score, nameMatchScore(symbol.ContainerName+"."+symbol.Name, query))
+	}
+	return score
+}
+
+func nameMatchScore(name, query string) int {
+	lowerName, lowerQuery := strings.ToLower(name), strings.ToLower(query)
+	switch {
+	case name == query:
+		return 0
+	case lowerName == lowerQuery:
+		return 1
+	case strings.HasPrefix(name, query):
+		return 2
+	case strings.HasPrefix(lowerName, lowerQuery):
+		return 3
+	case strings.Contains(name, query):
+		return 4
+	case strings.Contains(lowerName, lowerQuery):
+		return 5
+	}
+	return 6
+}
+
+// filterSymbolsByKind keeps the symbols of the given kind. Document symbols of
+// other kinds are replaced by their matching descendants.
+func filterSymbolsByKind(result any, kind string) any {
+	matches := func(k protocol.SymbolKind) bool {
+		return strings.EqualFold(symbolKindToString(k), kind)
+	}
+
+	switch symbols := result.(type) {
+	case []protocol.DocumentSymbol:
+		var filter func([]protocol.DocumentSymbol) []protocol.DocumentSymbol
+		filter = func(symbols []protocol.DocumentSymbol) []protocol.DocumentSymbol {
+			filtered := []protocol.DocumentSymbol{}
+			for _, symbol := range symbols {
+				children := filter(symbol.Children)
+				if matches(symbol.Kind) {
+					symbol.Children = children
+					filtered = append(filtered, symbol)
+				} else {
+					filtered = append(filtered, children...)
+				}
+			}
+			return filtered
+		}
+		return filter(symbols)
+	case []protocol.SymbolInformation:
+		filtered := []protocol.SymbolInformation{}
+		for _, symbol := range symbols {
+			if matches(symbol.Kind) {
+				filtered = append(filtered, symbol)
+			}
+		}
+		return filtered
+	}
+	return result
+}
+
 // formatSymbols converts the LSP symbol result (either []DocumentSymbol or []SymbolInformation) into a string slice.
 func formatSymbols(result any, showLineNumbers bool) []string { // Use any instead of interface{}
 	var formatted []string
@@ -160,6 +318,10 @@ func formatSymbols(result any, showLineNumbers bool) []string { // Use any inste
 		for _, symbol := range symbols {
 			formatted = append(formatted, formatSymbolInformation(symbol, showLineNumbers))
 		}
+	case []languageSymbol:
+		for _, symbol := range symbols {
+			formatted = append(formatted, fmt.Sprintf("[%s] %s", symbol.language, formatSymbolInformation(symbol.symbol, showLineNumbers)))
+		}
 	default:
 		// Should not happen if LSP client returns correctly
 		formatted = append(formatted, fmt.Sprintf("Error: Unexpected symbol result type %T", result))
@@ -168,6 +330,57 @@ func formatSymbols(result any, showLineNumbers bool) []string { // Use any inste
 	return formatted
 }
 
+// collectSymbols converts the LSP symbol result into symbols. Document symbols
+// are flattened, with the name of their parent as container, and located in uri.
+// Symbols without a language of their own are tagged with language.
+func collectSymbols(result any, uri protocol.DocumentUri, language string) []Symbol {
+	symbols := []Symbol{}
+
+	var walk func(symbol protocol.DocumentSymbol, container string)
+	walk = func(symbol protocol.DocumentSymbol, container string) {
+		symbols = append(symbols, Symbol{
+			Name:      symbol.Name,
+			Kind:      symbolKindToString(symbol.Kind),
+			Detail:    symbol.Detail,
+			Container: container,
+			Language:  language,
+			Location:  newLocation(uri, symbol.SelectionRange),
+		})
+		for _, child := range symbol.Children {
+			walk(child, symbol.Name)
+		}
+	}
+
+	switch v := result.(type) {
+	case []protocol.DocumentSymbol:
+		for _, symbol := range v {
+			walk(symbol, "")
+		}
+	case []protocol.SymbolInformation:
+		for _, symbol := range v {
+			symbols = append(symbols, newSymbolInformation(symbol, language))
+		}
+	case []languageSymbol:
+		for _, symbol := range v {
+			symbols = append(symbols, newSymbolInformation(symbol.symbol, symbol.language))
+		}
+	}
+
+	return symbols
+}
+
+// newSymbolInformation converts a SymbolInformation found by the language server
+// of language.
+func newSymbolInformation(symbol protocol.SymbolInformation, language string) Symbol {
+	return Symbol{
+		Name:      symbol.Name,
+		Kind:      symbolKindToString(symbol.Kind),
+		Container: symbol.ContainerName,
+		Language:  language,
+		Location:  newLocation(symbol.Location.URI, symbol.Location.Range),
+	}
+}
+
 // formatDocumentSymbol recursively formats DocumentSymbol and its children.
 func formatDocumentSymbol(symbol protocol.DocumentSymbol, prefix string, showLineNumbers bool) []string {
 	var results []string
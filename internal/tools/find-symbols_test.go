@@ -0,0 +1,83 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+)
+
+func symbolInfo(name string, kind protocol.SymbolKind, uri protocol.DocumentUri, line uint32) protocol.SymbolInformation {
+	return protocol.SymbolInformation{
+		Name: name,
+		Kind: kind,
+		Location: protocol.Location{
+			URI:   uri,
+			Range: protocol.Range{Start: protocol.Position{Line: line}},
+		},
+	}
+}
+
+func TestMergeWorkspaceSymbols(t *testing.T) {
+	byLanguage := map[string][]protocol.SymbolInformation{
+		"typescript": {
+			symbolInfo("createClient", protocol.Function, "file:///ws/web/client.ts", 3),
+			symbolInfo("Client", protocol.Class, "file:///ws/web/client.ts", 10),
+			// Also reported by the javascript server
+			symbolInfo("clientUtil", protocol.Function, "file:///ws/web/util.js", 1),
+		},
+		"go": {
+			symbolInfo("NewClient", protocol.Function, "file:///ws/lsp/client.go", 20),
+			symbolInfo("Client", protocol.Struct, "file:///ws/lsp/client.go", 5),
+		},
+		"javascript": {
+			symbolInfo("clientUtil", protocol.Function, "file:///ws/web/util.js", 1),
+		},
+	}
+
+	var got []string
+	for _, symbol := range mergeWorkspaceSymbols(byLanguage, "Client", "") {
+		got = append(got, symbol.language+":"+symbol.symbol.Name)
+	}
+	assert.Equal(t, []string{
+		"go:Client",
+		"typescript:Client",
+		"javascript:clientUtil",
+		"go:NewClient",
+		"typescript:createClient",
+	}, got)
+
+	got = nil
+	for _, symbol := range mergeWorkspaceSymbols(byLanguage, "Client", "function") {
+		got = append(got, symbol.language+":"+symbol.symbol.Name)
+	}
+	assert.Equal(t, []string{"javascript:clientUtil", "go:NewClient", "typescript:createClient"}, got)
+}
+
+func TestSymbolMatchScore(t *testing.T) {
+	method := symbolInfo("Call", protocol.Method, "file:///ws/client.go", 1)
+	method.ContainerName = "Client"
+
+	assert.Equal(t, 0, symbolMatchScore(method, "Call"))
+	assert.Equal(t, 1, symbolMatchScore(method, "call"))
+	assert.Equal(t, 0, symbolMatchScore(method, "Client.Call"))
+	assert.Equal(t, 3, symbolMatchScore(method, "client.ca"))
+	assert.Equal(t, 6, symbolMatchScore(method, "cll"))
+}
+
+func TestFilterSymbolsByKind(t *testing.T) {
+	symbols := []protocol.DocumentSymbol{
+		{
+			Name: "Client",
+			Kind: protocol.Struct,
+			Children: []protocol.DocumentSymbol{
+				{Name: "conn", Kind: protocol.Field},
+			},
+		},
+		{Name: "NewClient", Kind: protocol.Function},
+	}
+
+	filtered := filterSymbolsByKind(symbols, "field").([]protocol.DocumentSymbol)
+	assert.Len(t, filtered, 1)
+	assert.Equal(t, "conn", filtered[0].Name)
+}
@@ -0,0 +1,103 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/isaacphi/mcp-language-server/internal/utilities"
+)
+
+// FormatResult is the result of format_file.
+type FormatResult struct {
+	Path string `json:"path"`
+	// Changed is false if the file was already formatted
+	Changed bool   `json:"changed"`
+	Diff    string `json:"diff,omitempty"`
+}
+
+func (r *FormatResult) Text() string {
+	if !r.Changed {
+		return fmt.Sprintf("%s is already formatted", r.Path)
+	}
+	return fmt.Sprintf("Formatted %s\n%s", r.Path, r.Diff)
+}
+
+// FormatFile formats a file, or the given 1-based line range of it, using the
+// language server and returns a unified diff of the changes.
+func FormatFile(ctx context.Context, client *lsp.Client, filePath string, startLine, endLine int, options protocol.FormattingOptions) (*FormatResult, error) {
+	// Ensure filePath is absolute
+	absFilePath, err := filepath.Abs(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("could not get absolute path for '%s': %w", filePath, err)
+	}
+	filePath = absFilePath // Use absolute path from now on
+
+	err = client.OpenFile(ctx, filePath)
+	if err != nil {
+		return nil, fmt.Errorf("could not open file '%s': %w", filePath, err)
+	}
+
+	before, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	uri := protocol.DocumentUri("file://" + filePath)
+
+	var edits []protocol.TextEdit
+	if startLine > 0 {
+		if endLine < startLine {
+			endLine = startLine
+		}
+		rng, err := getRange(startLine, endLine, filePath)
+		if err != nil {
+			return nil, fmt.Errorf("invalid range: %v", err)
+		}
+		edits, err = client.RangeFormatting(ctx, protocol.DocumentRangeFormattingParams{
+			TextDocument: protocol.TextDocumentIdentifier{URI: uri},
+			Range:        rng,
+			Options:      options,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to format range: %w", err)
+		}
+	} else {
+		edits, err = client.Formatting(ctx, protocol.DocumentFormattingParams{
+			TextDocument: protocol.TextDocumentIdentifier{URI: uri},
+			Options:      options,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to format file: %w", err)
+		}
+	}
+
+	if len(edits) == 0 {
+		return &FormatResult{Path: filePath}, nil
+	}
+
+	edit := protocol.WorkspaceEdit{
+		Changes: map[protocol.DocumentUri][]protocol.TextEdit{
+			uri: edits,
+		},
+	}
+	if err := utilities.ApplyWorkspaceEdit(edit); err != nil {
+		return nil, fmt.Errorf("failed to apply formatting edits: %v", err)
+	}
+	notifyWorkspaceEdit(ctx, client, edit)
+
+	after, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read formatted file: %w", err)
+	}
+
+	diff := utilities.UnifiedDiff(filePath, string(before), string(after))
+	if diff == "" {
+		return &FormatResult{Path: filePath}, nil
+	}
+
+	return &FormatResult{Path: filePath, Changed: true, Diff: diff}, nil
+}
@@ -2,68 +2,64 @@ package tools
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"path/filepath" // Added for absolute path conversion
 	"strings"
 	"time"
-	"path/filepath" // Added for absolute path conversion
 
 	"github.com/isaacphi/mcp-language-server/internal/lsp"
 	"github.com/isaacphi/mcp-language-server/internal/protocol"
 )
 
-// GetCodeLens retrieves code lens hints for a given file location
-func GetCodeLens(ctx context.Context, client *lsp.Client, filePath string) (string, error) {
-	// Ensure filePath is absolute
-	absFilePath, err := filepath.Abs(filePath)
-	if err != nil {
-		return "", fmt.Errorf("could not get absolute path for '%s': %w", filePath, err)
-	}
-	filePath = absFilePath // Use absolute path from now on
+// CodeLensResult is the result of get_codelens.
+type CodeLensResult struct {
+	Path   string     `json:"path"`
+	Lenses []CodeLens `json:"lenses"`
 
-	err = client.OpenFile(ctx, filePath) // Use absolute path
-	if err != nil {
-		return "", fmt.Errorf("could not open file '%s': %w", filePath, err)
-	}
-	// TODO: find a more appropriate way to wait
-	time.Sleep(time.Second)
+	unavailable bool
+}
 
-	// Create document identifier
-	docIdentifier := protocol.TextDocumentIdentifier{
-		URI: protocol.DocumentUri("file://" + filePath),
-	}
+// CodeLens is a command shown above a line of code, such as "run test".
+type CodeLens struct {
+	// Index is the 1-based index to pass to execute_codelens
+	Index    int      `json:"index"`
+	Location Location `json:"location"`
+	// Title and Command are empty for lenses the server has not resolved yet
+	Title     string            `json:"title,omitempty"`
+	Command   string            `json:"command,omitempty"`
+	Arguments []json.RawMessage `json:"arguments,omitempty"`
+	// Data is server specific and may identify the provider of the lens
+	Data interface{} `json:"data,omitempty"`
 
-	// Request code lens from LSP
-	params := protocol.CodeLensParams{
-		TextDocument: docIdentifier,
-	}
-	codeLensResult, err := client.CodeLens(ctx, params)
-	if err != nil {
-		return "", fmt.Errorf("failed to get code lens: %w", err)
-	}
+	hasCommand bool
+}
 
-	if codeLensResult == nil {
-		return "No code lens providers available for this file.", nil
+func (r *CodeLensResult) Text() string {
+	if r.unavailable {
+		return "No code lens providers available for this file."
 	}
 
 	// Format the code lens results
 	var output strings.Builder
-	output.WriteString(fmt.Sprintf("Code Lens results for %s:\n", filePath))
+	output.WriteString(fmt.Sprintf("Code Lens results for %s:\n", r.Path))
 	output.WriteString(strings.Repeat("=", 80) + "\n\n")
 
-	for i, lens := range codeLensResult {
+	for _, lens := range r.Lenses {
 		output.WriteString(fmt.Sprintf("[%d] Location: Lines %d-%d\n",
-			i+1,
-			lens.Range.Start.Line+1,
-			lens.Range.End.Line+1))
+			lens.Index,
+			lens.Location.Line,
+			lens.Location.EndLine))
 
-		if lens.Command != nil {
-			output.WriteString(fmt.Sprintf("    Title: %s\n", lens.Command.Title))
-			if lens.Command.Command != "" {
-				output.WriteString(fmt.Sprintf("    Command: %s\n", lens.Command.Command))
+		if lens.hasCommand {
+			output.WriteString(fmt.Sprintf("    Title: %s\n", lens.Title))
+			if lens.Command != "" {
+				output.WriteString(fmt.Sprintf("    Command: %s\n", lens.Command))
 			}
-			if lens.Command.Arguments != nil {
+			if lens.Arguments != nil {
 				output.WriteString("    Arguments:\n")
-				for _, arg := range lens.Command.Arguments {
+				for _, arg := range lens.Arguments {
 					output.WriteString(fmt.Sprintf("%s\n", arg))
 				}
 			}
@@ -77,11 +73,90 @@ func GetCodeLens(ctx context.Context, client *lsp.Client, filePath string) (stri
 		output.WriteString("\n")
 	}
 
-	if len(codeLensResult) == 0 {
+	if len(r.Lenses) == 0 {
 		output.WriteString("No code lens found for this file.\n")
 	} else {
-		output.WriteString(fmt.Sprintf("Found %d code lens items.\n", len(codeLensResult)))
+		output.WriteString(fmt.Sprintf("Found %d code lens items.\n", len(r.Lenses)))
+	}
+
+	return output.String()
+}
+
+// GetCodeLens retrieves code lens hints for a given file location
+func GetCodeLens(ctx context.Context, client *lsp.Client, filePath string) (*CodeLensResult, error) {
+	// Ensure filePath is absolute
+	absFilePath, err := filepath.Abs(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("could not get absolute path for '%s': %w", filePath, err)
 	}
+	filePath = absFilePath // Use absolute path from now on
+
+	err = client.OpenFile(ctx, filePath) // Use absolute path
+	if err != nil {
+		return nil, fmt.Errorf("could not open file '%s': %w", filePath, err)
+	}
+
+	// Create document identifier
+	docIdentifier := protocol.TextDocumentIdentifier{
+		URI: protocol.DocumentUri("file://" + filePath),
+	}
+
+	// Request code lens from LSP
+	codeLensResult, err := requestCodeLenses(ctx, client, docIdentifier)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get code lens: %w", err)
+	}
+
+	result := &CodeLensResult{
+		Path:        filePath,
+		Lenses:      []CodeLens{},
+		unavailable: codeLensResult == nil,
+	}
+	for i, lens := range codeLensResult {
+		item := CodeLens{
+			Index:    i + 1,
+			Location: newLocation(docIdentifier.URI, lens.Range),
+			Data:     lens.Data,
+		}
+		if lens.Command != nil {
+			item.hasCommand = true
+			item.Title = lens.Command.Title
+			item.Command = lens.Command.Command
+			item.Arguments = lens.Command.Arguments
+		}
+		result.Lenses = append(result.Lenses, item)
+	}
+
+	return result, nil
+}
+
+// Servers may not have processed a file they were just sent yet, in which case
+// they return no code lenses or fail with ContentModified. The request is then
+// repeated up to codeLensAttempts times, codeLensRetryDelay apart.
+const (
+	codeLensAttempts   = 5
+	codeLensRetryDelay = 200 * time.Millisecond
+)
+
+// requestCodeLenses requests the code lenses of a document, retrying while the
+// server returns none or reports that the content was modified.
+func requestCodeLenses(ctx context.Context, client *lsp.Client, document protocol.TextDocumentIdentifier) ([]protocol.CodeLens, error) {
+	params := protocol.CodeLensParams{
+		TextDocument: document,
+	}
+	for attempt := 1; ; attempt++ {
+		lenses, err := client.CodeLens(ctx, params)
 
-	return output.String(), nil
+		var responseErr *lsp.ResponseError
+		contentModified := errors.As(err, &responseErr) && responseErr.Code == int(protocol.ContentModified)
+		if attempt == codeLensAttempts || (err != nil && !contentModified) || (err == nil && len(lenses) > 0) {
+			return lenses, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(codeLensRetryDelay):
+		}
+	}
 }
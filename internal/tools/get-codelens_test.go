@@ -0,0 +1,79 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetCodeLensRetries(t *testing.T) {
+	path := writeTestFile(t, "main_test.go", "package main\n\nfunc TestMain(t *testing.T) {}\n")
+	testLens := protocol.CodeLens{
+		Range:   lineRange(2, 0, 2, 30),
+		Command: &protocol.Command{Title: "run test", Command: "test.run"},
+	}
+
+	tests := []struct {
+		name         string
+		responses    []any
+		wantLenses   int
+		wantRequests int
+		wantErr      string
+	}{
+		{
+			name:         "content modified, then empty, then lenses",
+			responses:    []any{&lsp.ResponseError{Code: int(protocol.ContentModified), Message: "content modified"}, []protocol.CodeLens{}, []protocol.CodeLens{testLens}},
+			wantLenses:   1,
+			wantRequests: 3,
+		},
+		{
+			name:         "lenses right away",
+			responses:    []any{[]protocol.CodeLens{testLens}},
+			wantLenses:   1,
+			wantRequests: 1,
+		},
+		{
+			name:         "other errors are not retried",
+			responses:    []any{&lsp.ResponseError{Code: -32603, Message: "internal error"}},
+			wantRequests: 1,
+			wantErr:      "internal error (code: -32603)",
+		},
+		{
+			name:         "gives up on an empty result",
+			responses:    []any{},
+			wantRequests: codeLensAttempts,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			client, server := newFakeClient(t, protocol.ServerCapabilities{}, map[string]fakeHandler{
+				"textDocument/codeLens": func(json.RawMessage) (any, error) {
+					calls++
+					if calls > len(tt.responses) {
+						return []protocol.CodeLens{}, nil
+					}
+					if err, ok := tt.responses[calls-1].(*lsp.ResponseError); ok {
+						return nil, err
+					}
+					return tt.responses[calls-1], nil
+				},
+			})
+
+			result, err := GetCodeLens(context.Background(), client, path)
+			assert.Equal(t, tt.wantRequests, server.requestCount("textDocument/codeLens"))
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Len(t, result.Lenses, tt.wantLenses)
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// HoverResult is the result of hover.
+type HoverResult struct {
+	Position Location `json:"position"`
+	// Contents is the type signature and documentation, usually markdown
+	Contents string `json:"contents"`
+}
+
+func (r *HoverResult) Text() string {
+	if r.Contents == "" {
+		return fmt.Sprintf("No hover information available at %s", r.Position)
+	}
+
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("Hover information for %s\n", r.Position))
+	output.WriteString(strings.Repeat("=", 80) + "\n")
+	output.WriteString(r.Contents)
+	output.WriteString("\n")
+
+	return output.String()
+}
+
+// GetHoverInfo returns the hover information (type signature and documentation)
+// for the symbol at the given position or with the given name.
+func GetHoverInfo(ctx context.Context, client *lsp.Client, filePath string, line, column int, symbolName string) (*HoverResult, error) {
+	position, err := resolvePosition(ctx, client, filePath, line, column, symbolName)
+	if err != nil {
+		return nil, err
+	}
+
+	hover, err := client.Hover(ctx, protocol.HoverParams{
+		TextDocumentPositionParams: position,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get hover information: %w", err)
+	}
+
+	return &HoverResult{
+		Position: newPosition(position.TextDocument.URI, position.Position),
+		Contents: renderMarkupContent(hover.Contents),
+	}, nil
+}
+
+// renderMarkupContent returns the text of a MarkupContent. Markdown is returned
+// as-is since fenced code blocks read well in tool output, and plaintext is
+// returned unchanged apart from surrounding whitespace.
+func renderMarkupContent(content protocol.MarkupContent) string {
+	return strings.TrimSpace(content.Value)
+}
+
+// renderDocumentation returns the text of a documentation value, which servers
+// send either as a plain string or as MarkupContent.
+func renderDocumentation(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case protocol.MarkupContent:
+		return renderMarkupContent(v)
+	}
+	return ""
+}
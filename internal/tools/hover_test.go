@@ -0,0 +1,59 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderDocumentation(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want  string
+	}{
+		{"string", "  Adds two numbers.\n", "Adds two numbers."},
+		{"markdown", protocol.MarkupContent{Kind: protocol.Markdown, Value: "```go\nfunc Add(a, b int) int\n```\n"}, "```go\nfunc Add(a, b int) int\n```"},
+		{"plaintext", protocol.MarkupContent{Kind: protocol.PlainText, Value: "\nfunc Add(a, b int) int"}, "func Add(a, b int) int"},
+		{"missing", nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, renderDocumentation(tt.value))
+		})
+	}
+}
+
+func TestHoverResultText(t *testing.T) {
+	position := Location{Path: "/ws/main.go", Line: 3, Column: 6}
+
+	empty := &HoverResult{Position: position}
+	assert.Equal(t, "No hover information available at /ws/main.go:3:6", empty.Text())
+
+	result := &HoverResult{Position: position, Contents: "func Add(a, b int) int"}
+	assert.Equal(t, "Hover information for /ws/main.go:3:6\n"+
+		"================================================================================\n"+
+		"func Add(a, b int) int\n", result.Text())
+}
+
+func TestGetHoverInfo(t *testing.T) {
+	path := writeTestFile(t, "main.go", "package main\n\nfunc Add(a, b int) int { return a + b }\n")
+
+	var requested protocol.HoverParams
+	client, _ := newFakeClient(t, protocol.ServerCapabilities{}, map[string]fakeHandler{
+		"textDocument/hover": func(params json.RawMessage) (any, error) {
+			require.NoError(t, json.Unmarshal(params, &requested))
+			return protocol.Hover{Contents: protocol.MarkupContent{Kind: protocol.Markdown, Value: "func Add(a, b int) int\n"}}, nil
+		},
+	})
+
+	result, err := GetHoverInfo(context.Background(), client, path, 3, 6, "")
+	require.NoError(t, err)
+	assert.Equal(t, "func Add(a, b int) int", result.Contents)
+	assert.Equal(t, Location{Path: path, Line: 3, Column: 6}, result.Position)
+	assert.Equal(t, protocol.Position{Line: 2, Character: 5}, requested.Position)
+}
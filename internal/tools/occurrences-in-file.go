@@ -0,0 +1,126 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// OccurrencesResult is the result of occurrences_in_file.
+type OccurrencesResult struct {
+	// Symbol is the text of the first occurrence, or the requested name
+	Symbol      string       `json:"symbol"`
+	Position    Location     `json:"position"`
+	Occurrences []Occurrence `json:"occurrences"`
+}
+
+// Occurrence is a use of a symbol within its file.
+type Occurrence struct {
+	Location Location `json:"location"`
+	// Kind is "read", "write" or "text"
+	Kind string `json:"kind"`
+	// Line is the source line the occurrence is on
+	Line string `json:"line"`
+}
+
+func (r *OccurrencesResult) Text() string {
+	if len(r.Occurrences) == 0 {
+		return fmt.Sprintf("No occurrences found at %s", r.Position)
+	}
+
+	counts := make(map[string]int)
+	var entries strings.Builder
+	for _, occurrence := range r.Occurrences {
+		counts[occurrence.Kind]++
+		entries.WriteString(fmt.Sprintf("%5d:%-4d %-7s | %s\n",
+			occurrence.Location.Line,
+			occurrence.Location.Column,
+			"["+occurrence.Kind+"]",
+			occurrence.Line))
+	}
+
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("Occurrences of '%s' in %s: %d (%d write, %d read, %d text)\n",
+		r.Symbol, r.Position.Path, len(r.Occurrences), counts["write"], counts["read"], counts["text"]))
+	output.WriteString(strings.Repeat("=", 80) + "\n")
+	output.WriteString(entries.String())
+
+	return output.String()
+}
+
+// FindOccurrencesInFile lists the occurrences of the symbol at the given position
+// or with the given name within its file, classified as read, write or text
+// occurrences, each with the line it appears on.
+func FindOccurrencesInFile(ctx context.Context, client *lsp.Client, filePath string, line, column int, symbolName string) (*OccurrencesResult, error) {
+	position, err := resolvePosition(ctx, client, filePath, line, column, symbolName)
+	if err != nil {
+		return nil, err
+	}
+
+	highlights, err := client.DocumentHighlight(ctx, protocol.DocumentHighlightParams{
+		TextDocumentPositionParams: position,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get document highlights: %w", err)
+	}
+
+	result := &OccurrencesResult{
+		Symbol:      symbolName,
+		Position:    newPosition(position.TextDocument.URI, position.Position),
+		Occurrences: []Occurrence{},
+	}
+	if len(highlights) == 0 {
+		return result, nil
+	}
+
+	content, err := os.ReadFile(result.Position.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	lines := strings.Split(string(content), "\n")
+
+	sort.Slice(highlights, func(i, j int) bool {
+		a, b := highlights[i].Range.Start, highlights[j].Range.Start
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Character < b.Character
+	})
+
+	name, err := ExtractTextFromLocation(protocol.Location{URI: position.TextDocument.URI, Range: highlights[0].Range})
+	if err == nil && name != "" {
+		result.Symbol = name
+	}
+
+	for _, highlight := range highlights {
+		lineText := ""
+		if int(highlight.Range.Start.Line) < len(lines) {
+			lineText = strings.TrimRight(lines[highlight.Range.Start.Line], "\r")
+		}
+		result.Occurrences = append(result.Occurrences, Occurrence{
+			Location: newLocation(position.TextDocument.URI, highlight.Range),
+			Kind:     highlightKindToString(highlight.Kind),
+			Line:     lineText,
+		})
+	}
+
+	return result, nil
+}
+
+// highlightKindToString returns "read", "write" or "text". Servers may omit the
+// kind, which defaults to text.
+func highlightKindToString(kind protocol.DocumentHighlightKind) string {
+	switch kind {
+	case protocol.Read:
+		return "read"
+	case protocol.Write:
+		return "write"
+	default:
+		return "text"
+	}
+}
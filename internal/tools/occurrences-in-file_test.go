@@ -0,0 +1,65 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHighlightKindToString(t *testing.T) {
+	tests := []struct {
+		kind protocol.DocumentHighlightKind
+		want string
+	}{
+		{protocol.Read, "read"},
+		{protocol.Write, "write"},
+		{protocol.Text, "text"},
+		{0, "text"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, highlightKindToString(tt.kind))
+	}
+}
+
+func TestFindOccurrencesInFile(t *testing.T) {
+	path := writeTestFile(t, "count.go", "package main\n\nfunc count() int {\n\ttotal := 0\n\ttotal++\n\treturn total\n}\n")
+	client, _ := newFakeClient(t, protocol.ServerCapabilities{}, map[string]fakeHandler{
+		// Unsorted and partly without a kind, as some servers send them
+		"textDocument/documentHighlight": func(json.RawMessage) (any, error) {
+			return []protocol.DocumentHighlight{
+				{Range: lineRange(5, 8, 5, 13), Kind: protocol.Read},
+				{Range: lineRange(3, 1, 3, 6), Kind: protocol.Write},
+				{Range: lineRange(4, 1, 4, 6)},
+			}, nil
+		},
+	})
+
+	result, err := FindOccurrencesInFile(context.Background(), client, path, 4, 2, "")
+	require.NoError(t, err)
+	assert.Equal(t, "total", result.Symbol)
+	assert.Equal(t, []Occurrence{
+		{Location: Location{Path: path, Line: 4, Column: 2, EndLine: 4, EndColumn: 7}, Kind: "write", Line: "\ttotal := 0"},
+		{Location: Location{Path: path, Line: 5, Column: 2, EndLine: 5, EndColumn: 7}, Kind: "text", Line: "\ttotal++"},
+		{Location: Location{Path: path, Line: 6, Column: 9, EndLine: 6, EndColumn: 14}, Kind: "read", Line: "\treturn total"},
+	}, result.Occurrences)
+
+	assert.Equal(t, "Occurrences of 'total' in "+path+": 3 (1 write, 1 read, 1 text)\n"+
+		"================================================================================\n"+
+		"    4:2    [write] | \ttotal := 0\n"+
+		"    5:2    [text]  | \ttotal++\n"+
+		"    6:9    [read]  | \treturn total\n", result.Text())
+}
+
+func TestFindOccurrencesInFileNone(t *testing.T) {
+	path := writeTestFile(t, "count.go", "package main\n")
+	client, _ := newFakeClient(t, protocol.ServerCapabilities{}, nil)
+
+	result, err := FindOccurrencesInFile(context.Background(), client, path, 1, 1, "")
+	require.NoError(t, err)
+	assert.Empty(t, result.Occurrences)
+	assert.Equal(t, "No occurrences found at "+path+":1:1", result.Text())
+}
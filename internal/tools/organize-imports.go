@@ -0,0 +1,142 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/isaacphi/mcp-language-server/internal/utilities"
+)
+
+// Outcomes of organizing the imports of a file.
+const (
+	ImportsOrganized   = "organized"
+	ImportsUnchanged   = "unchanged"
+	ImportsUnavailable = "unavailable"
+	ImportsFailed      = "error"
+)
+
+// OrganizeImportsResult is the result of organize_imports.
+type OrganizeImportsResult struct {
+	Files []OrganizedFile `json:"files"`
+}
+
+// OrganizedFile is the outcome of organizing the imports of one file.
+type OrganizedFile struct {
+	Path string `json:"path"`
+	// Status is "organized", "unchanged", "unavailable" if the server has no
+	// organize imports action for the file, or "error"
+	Status string `json:"status"`
+	Diff   string `json:"diff,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+func (r *OrganizeImportsResult) Text() string {
+	if len(r.Files) == 0 {
+		return "No open files to organize"
+	}
+
+	var output strings.Builder
+	changed := 0
+	for _, file := range r.Files {
+		switch file.Status {
+		case ImportsFailed:
+			// Keep going so one bad file doesn't block the rest
+			output.WriteString(fmt.Sprintf("Error organizing imports in %s: %s\n", file.Path, file.Error))
+		case ImportsUnavailable:
+			output.WriteString(fmt.Sprintf("No organize imports action available for %s\n", file.Path))
+		case ImportsUnchanged:
+			output.WriteString(fmt.Sprintf("Imports in %s are already organized\n", file.Path))
+		case ImportsOrganized:
+			changed++
+			output.WriteString(fmt.Sprintf("Organized imports in %s\n%s\n", file.Path, file.Diff))
+		}
+	}
+
+	if changed > 0 {
+		output.WriteString(fmt.Sprintf("Organized imports in %d of %d file(s)\n", changed, len(r.Files)))
+		output.WriteString("WARNING: line numbers may have changed. Re-read code before applying additional edits.")
+	}
+
+	return strings.TrimSuffix(output.String(), "\n")
+}
+
+// OrganizeImports applies the language server's source.organizeImports action to
+// each of the given files and returns a unified diff per changed file. With no
+// files, every file the client currently has open is processed.
+func OrganizeImports(ctx context.Context, client *lsp.Client, filePaths []string) (*OrganizeImportsResult, error) {
+	if len(filePaths) == 0 {
+		filePaths = client.OpenFilePaths()
+	}
+
+	result := &OrganizeImportsResult{Files: []OrganizedFile{}}
+	for _, filePath := range filePaths {
+		file, err := organizeImportsInFile(ctx, client, filePath)
+		if err != nil {
+			// Keep going so one bad file doesn't block the rest
+			file = OrganizedFile{Path: filePath, Status: ImportsFailed, Error: err.Error()}
+		}
+		result.Files = append(result.Files, file)
+	}
+
+	return result, nil
+}
+
+// organizeImportsInFile requests and applies the organize imports action for a
+// single file.
+func organizeImportsInFile(ctx context.Context, client *lsp.Client, filePath string) (OrganizedFile, error) {
+	absFilePath, err := filepath.Abs(filePath)
+	if err != nil {
+		return OrganizedFile{}, fmt.Errorf("could not get absolute path for '%s': %w", filePath, err)
+	}
+	filePath = absFilePath
+
+	err = client.OpenFile(ctx, filePath)
+	if err != nil {
+		return OrganizedFile{}, fmt.Errorf("could not open file '%s': %w", filePath, err)
+	}
+
+	before, err := os.ReadFile(filePath)
+	if err != nil {
+		return OrganizedFile{}, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	// Request the action for the whole file; getRange clamps the end line
+	actions, err := getCodeActions(ctx, client, filePath, 1, math.MaxInt32, "", []string{string(protocol.SourceOrganizeImports)})
+	if err != nil {
+		return OrganizedFile{}, err
+	}
+
+	var action *protocol.CodeAction
+	for i := range actions {
+		// Servers may return sub-kinds such as source.organizeImports.ts
+		if actions[i].Kind == protocol.SourceOrganizeImports || strings.HasPrefix(string(actions[i].Kind), string(protocol.SourceOrganizeImports)+".") {
+			action = &actions[i]
+			break
+		}
+	}
+	if action == nil {
+		return OrganizedFile{Path: filePath, Status: ImportsUnavailable}, nil
+	}
+
+	if _, err := resolveAndApplyCodeAction(ctx, client, *action); err != nil {
+		return OrganizedFile{}, err
+	}
+
+	after, err := os.ReadFile(filePath)
+	if err != nil {
+		return OrganizedFile{}, fmt.Errorf("failed to read updated file: %w", err)
+	}
+
+	diff := utilities.UnifiedDiff(filePath, string(before), string(after))
+	if diff == "" {
+		return OrganizedFile{Path: filePath, Status: ImportsUnchanged}, nil
+	}
+
+	return OrganizedFile{Path: filePath, Status: ImportsOrganized, Diff: diff}, nil
+}
@@ -10,20 +10,82 @@ import (
 	"github.com/isaacphi/mcp-language-server/internal/protocol"
 )
 
-func ReadDefinition(ctx context.Context, client *lsp.Client, symbolName string, showLineNumbers bool) (string, error) {
+// DefinitionsResult is the result of read_definition.
+type DefinitionsResult struct {
+	Symbol      string   `json:"symbol"`
+	Definitions []Symbol `json:"definitions"`
+
+	showLineNumbers bool
+	showLanguage    bool
+}
+
+func (r *DefinitionsResult) Text() string {
+	if len(r.Definitions) == 0 {
+		return fmt.Sprintf("%s not found", r.Symbol)
+	}
+
+	var definitions []string
+	for _, symbol := range r.Definitions {
+		kind := ""
+		if symbol.Kind != "" {
+			kind = fmt.Sprintf("Kind: %s\n", symbol.Kind)
+		}
+		container := ""
+		if symbol.Container != "" {
+			container = fmt.Sprintf("Container Name: %s\n", symbol.Container)
+		}
+		language := ""
+		if r.showLanguage {
+			language = fmt.Sprintf("Language: %s\n", symbol.Language)
+		}
+
+		banner := strings.Repeat("=", 80) + "\n"
+		locationInfo := fmt.Sprintf(
+			"Symbol: %s\n"+
+				"File: %s\n"+
+				language+
+				kind+
+				container+
+				"Start Position: Line %d, Column %d\n"+
+				"End Position: Line %d, Column %d\n"+
+				"%s\n",
+			symbol.Name,
+			symbol.Location.Path,
+			symbol.Location.Line,
+			symbol.Location.Column,
+			symbol.Location.EndLine,
+			symbol.Location.EndColumn,
+			strings.Repeat("=", 80))
+
+		definition := symbol.Snippet
+		if r.showLineNumbers {
+			definition = addLineNumbers(definition, symbol.Location.Line)
+		}
+
+		definitions = append(definitions, banner+locationInfo+definition+"\n")
+	}
+
+	return strings.Join(definitions, "\n")
+}
+
+func ReadDefinition(ctx context.Context, client *lsp.Client, symbolName string, showLineNumbers bool) (*DefinitionsResult, error) {
 	symbolResult, err := client.Symbol(ctx, protocol.WorkspaceSymbolParams{
 		Query: symbolName,
 	})
 	if err != nil {
-		return "", fmt.Errorf("Failed to fetch symbol: %v", err)
+		return nil, fmt.Errorf("Failed to fetch symbol: %v", err)
 	}
 
 	results, err := symbolResult.Results()
 	if err != nil {
-		return "", fmt.Errorf("Failed to parse results: %v", err)
+		return nil, fmt.Errorf("Failed to parse results: %v", err)
 	}
 
-	var definitions []string
+	result := &DefinitionsResult{
+		Symbol:          symbolName,
+		Definitions:     []Symbol{},
+		showLineNumbers: showLineNumbers,
+	}
 	for _, symbol := range results {
 		kind := ""
 		container := ""
@@ -33,10 +95,8 @@ func ReadDefinition(ctx context.Context, client *lsp.Client, symbolName string,
 		switch v := symbol.(type) {
 		case *protocol.SymbolInformation:
 			// SymbolInformation results have richer data.
-			kind = fmt.Sprintf("Kind: %s\n", protocol.TableKindMap[v.Kind])
-			if v.ContainerName != "" {
-				container = fmt.Sprintf("Container Name: %s\n", v.ContainerName)
-			}
+			kind = protocol.TableKindMap[v.Kind]
+			container = v.ContainerName
 			if v.Kind == protocol.Method && strings.HasSuffix(symbol.GetName(), symbolName) {
 				break
 			}
@@ -52,39 +112,62 @@ func ReadDefinition(ctx context.Context, client *lsp.Client, symbolName string,
 		log.Printf("Symbol: %s\n", symbol.GetName())
 		loc := symbol.GetLocation()
 
-		banner := strings.Repeat("=", 80) + "\n"
 		definition, loc, err := GetFullDefinition(ctx, client, loc)
-		locationInfo := fmt.Sprintf(
-			"Symbol: %s\n"+
-				"File: %s\n"+
-				kind+
-				container+
-				"Start Position: Line %d, Column %d\n"+
-				"End Position: Line %d, Column %d\n"+
-				"%s\n",
-			symbol.GetName(),
-			strings.TrimPrefix(string(loc.URI), "file://"),
-			loc.Range.Start.Line+1,
-			loc.Range.Start.Character+1,
-			loc.Range.End.Line+1,
-			loc.Range.End.Character+1,
-			strings.Repeat("=", 80))
-
 		if err != nil {
 			log.Printf("Error getting definition: %v\n", err)
 			continue
 		}
 
-		if showLineNumbers {
-			definition = addLineNumbers(definition, int(loc.Range.Start.Line)+1)
-		}
-
-		definitions = append(definitions, banner+locationInfo+definition+"\n")
+		result.Definitions = append(result.Definitions, Symbol{
+			Name:      symbol.GetName(),
+			Kind:      kind,
+			Container: container,
+			Location:  newLocation(loc.URI, loc.Range),
+			Snippet:   definition,
+		})
 	}
 
-	if len(definitions) == 0 {
-		return fmt.Sprintf("%s not found", symbolName), nil
+	return result, nil
+}
+
+// ReadDefinitionInLanguages looks up the definitions of a symbol with each of
+// the given language servers concurrently and merges them, tagging every
+// definition with the language of the server that found it.
+func ReadDefinitionInLanguages(ctx context.Context, clients map[string]*lsp.Client, symbolName string, showLineNumbers bool) (*DefinitionsResult, error) {
+	byLanguage, err := queryLanguages(ctx, clients, func(ctx context.Context, client *lsp.Client) ([]Symbol, error) {
+		result, err := ReadDefinition(ctx, client, symbolName, showLineNumbers)
+		if err != nil {
+			return nil, err
+		}
+		return result.Definitions, nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return strings.Join(definitions, "\n"), nil
+	return &DefinitionsResult{
+		Symbol:          symbolName,
+		Definitions:     mergeDefinitions(byLanguage),
+		showLineNumbers: showLineNumbers,
+		showLanguage:    len(clients) > 1,
+	}, nil
+}
+
+// mergeDefinitions combines the definitions found by each language server. A
+// definition reported by several servers is kept once, for the first language
+// in alphabetical order.
+func mergeDefinitions(byLanguage map[string][]Symbol) []Symbol {
+	merged := []Symbol{}
+	seen := make(map[Location]bool)
+	for _, language := range sortedLanguages(byLanguage) {
+		for _, symbol := range byLanguage[language] {
+			if seen[symbol.Location] {
+				continue
+			}
+			seen[symbol.Location] = true
+			symbol.Language = language
+			merged = append(merged, symbol)
+		}
+	}
+	return merged
 }
@@ -0,0 +1,36 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeDefinitions(t *testing.T) {
+	shared := Location{Path: "/ws/web/client.ts", Line: 3, Column: 1, EndLine: 9, EndColumn: 2}
+	byLanguage := map[string][]Symbol{
+		"typescript": {
+			{Name: "Client", Location: shared},
+		},
+		"javascript": {
+			{Name: "Client", Location: shared},
+		},
+		"go": {
+			{Name: "Client", Location: Location{Path: "/ws/lsp/client.go", Line: 12, Column: 1}},
+		},
+	}
+
+	merged := mergeDefinitions(byLanguage)
+
+	var got []string
+	for _, symbol := range merged {
+		got = append(got, symbol.Language+":"+symbol.Location.Path)
+	}
+	// The definition both TypeScript and JavaScript servers report is kept once
+	assert.Equal(t, []string{
+		"go:/ws/lsp/client.go",
+		"javascript:/ws/web/client.ts",
+	}, got)
+
+	assert.NotNil(t, mergeDefinitions(nil))
+}
@@ -0,0 +1,260 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// lineSpan is an inclusive, 0-based range of lines.
+type lineSpan struct {
+	Start uint32
+	End   uint32
+}
+
+// EnclosingBlockResult is the result of read_enclosing_block.
+type EnclosingBlockResult struct {
+	// Position is the position the block encloses
+	Position Location `json:"position"`
+	// Found is false if no block encloses the position
+	Found bool `json:"found"`
+	// Level is the number of blocks between the returned block and the innermost one
+	Level int `json:"level"`
+	// Levels is the number of enclosing blocks
+	Levels int `json:"levels"`
+	// Source is "selection ranges" or "folding ranges"
+	Source    string `json:"source,omitempty"`
+	StartLine int    `json:"startLine,omitempty"`
+	EndLine   int    `json:"endLine,omitempty"`
+	Content   string `json:"content,omitempty"`
+
+	requestedLevel  int
+	showLineNumbers bool
+}
+
+func (r *EnclosingBlockResult) Text() string {
+	if !r.Found {
+		return fmt.Sprintf("No enclosing block found at %s", r.Position)
+	}
+
+	note := ""
+	if r.requestedLevel > r.Level {
+		note = fmt.Sprintf(" (only %d levels available)", r.Levels)
+	}
+
+	text := r.Content
+	if r.showLineNumbers {
+		text = addLineNumbers(text, r.StartLine)
+	} else {
+		text += "\n"
+	}
+
+	var output strings.Builder
+	output.WriteString(strings.Repeat("=", 80) + "\n")
+	output.WriteString(fmt.Sprintf("Enclosing block of %s\n", r.Position))
+	output.WriteString(fmt.Sprintf("Level: %d of %d%s (from %s)\n", r.Level, r.Levels-1, note, r.Source))
+	output.WriteString(fmt.Sprintf("Lines: %d-%d\n", r.StartLine, r.EndLine))
+	output.WriteString(strings.Repeat("=", 80) + "\n")
+	output.WriteString(text)
+
+	return output.String()
+}
+
+// ReadEnclosingBlock returns the smallest syntactic block spanning several lines
+// around the given position, or the block levels steps further out. Blocks come
+// from the server's selection ranges, or from its folding ranges if selection
+// ranges are not supported.
+func ReadEnclosingBlock(ctx context.Context, client *lsp.Client, filePath string, line, column int, symbolName string, levels int, showLineNumbers bool) (*EnclosingBlockResult, error) {
+	position, err := resolvePosition(ctx, client, filePath, line, column, symbolName)
+	if err != nil {
+		return nil, err
+	}
+
+	path := strings.TrimPrefix(string(position.TextDocument.URI), "file://")
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	lines := strings.Split(strings.ReplaceAll(string(content), "\r\n", "\n"), "\n")
+
+	blocks, source, err := enclosingBlocks(ctx, client, position, lines)
+	if err != nil {
+		return nil, err
+	}
+	result := &EnclosingBlockResult{
+		Position:        newPosition(position.TextDocument.URI, position.Position),
+		Levels:          len(blocks),
+		Source:          source,
+		showLineNumbers: showLineNumbers,
+	}
+	if len(blocks) == 0 {
+		return result, nil
+	}
+
+	if levels < 0 {
+		levels = 0
+	}
+	result.requestedLevel = levels
+	if levels >= len(blocks) {
+		levels = len(blocks) - 1
+	}
+	block := blocks[levels]
+
+	result.Found = true
+	result.Level = levels
+	result.StartLine = int(block.Start) + 1
+	result.EndLine = int(block.End) + 1
+	result.Content = strings.Join(lines[block.Start:block.End+1], "\n")
+
+	return result, nil
+}
+
+// enclosingBlocks returns the distinct multi-line blocks containing position,
+// innermost first, and the name of the request they came from.
+func enclosingBlocks(ctx context.Context, client *lsp.Client, position protocol.TextDocumentPositionParams, lines []string) ([]lineSpan, string, error) {
+	capabilities := client.ServerCapabilities()
+
+	if supportsProvider(capabilities.SelectionRangeProvider) {
+		ranges, err := client.SelectionRange(ctx, protocol.SelectionRangeParams{
+			TextDocument: position.TextDocument,
+			Positions:    []protocol.Position{position.Position},
+		})
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to get selection ranges: %w", err)
+		}
+		if len(ranges) > 0 {
+			var blocks []lineSpan
+			for sr := &ranges[0]; sr != nil; sr = sr.Parent {
+				blocks = appendBlock(blocks, lineSpan{Start: sr.Range.Start.Line, End: sr.Range.End.Line})
+			}
+			if len(blocks) > 0 {
+				return blocks, "selection ranges", nil
+			}
+		}
+	}
+
+	if supportsProvider(capabilities.FoldingRangeProvider) {
+		ranges, err := client.FoldingRange(ctx, protocol.FoldingRangeParams{
+			TextDocument: position.TextDocument,
+		})
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to get folding ranges: %w", err)
+		}
+
+		var spans []lineSpan
+		for _, fr := range ranges {
+			span := foldingRangeSpan(fr, lines)
+			if span.Start <= position.Position.Line && position.Position.Line <= span.End {
+				spans = append(spans, span)
+			}
+		}
+		// Innermost first
+		sort.SliceStable(spans, func(i, j int) bool {
+			return spans[i].End-spans[i].Start < spans[j].End-spans[j].Start
+		})
+
+		var blocks []lineSpan
+		for _, span := range spans {
+			blocks = appendBlock(blocks, span)
+		}
+		return blocks, "folding ranges", nil
+	}
+
+	return nil, "", fmt.Errorf("the language server supports neither selection ranges nor folding ranges")
+}
+
+// appendBlock appends span to blocks if it spans several lines and differs from
+// the last block.
+func appendBlock(blocks []lineSpan, span lineSpan) []lineSpan {
+	if span.End <= span.Start {
+		return blocks
+	}
+	if len(blocks) > 0 && blocks[len(blocks)-1] == span {
+		return blocks
+	}
+	return append(blocks, span)
+}
+
+// foldingRangeSpan returns the lines of a folding range. Folding ranges usually
+// stop before the closing bracket so that it stays visible when folded, so a
+// following line that starts with a closing bracket is included.
+func foldingRangeSpan(fr protocol.FoldingRange, lines []string) lineSpan {
+	span := lineSpan{Start: fr.StartLine, End: fr.EndLine}
+	if next := int(fr.EndLine) + 1; next < len(lines) {
+		trimmed := strings.TrimSpace(lines[next])
+		if trimmed != "" && strings.ContainsAny(trimmed[:1], ")]}") {
+			span.End++
+		}
+	}
+	return span
+}
+
+// supportsProvider reports whether a server capability that is either a bool
+// or an options object is enabled.
+func supportsProvider(provider interface{}) bool {
+	switch v := provider.(type) {
+	case *protocol.Or_ServerCapabilities_selectionRangeProvider:
+		return v != nil && v.Value != nil && v.Value != false
+	case *protocol.Or_ServerCapabilities_foldingRangeProvider:
+		return v != nil && v.Value != nil && v.Value != false
+	}
+	return false
+}
+
+// blockEnd asks the server where the block that opens on the last line of rng
+// ends, e.g. the closing parenthesis of a `const (` group whose symbol range
+// only covers the first line. It returns false if the server supports neither
+// selection ranges nor folding ranges, or if rng already covers the whole block.
+func blockEnd(ctx context.Context, client *lsp.Client, uri protocol.DocumentUri, rng protocol.Range, lines []string) (protocol.Position, bool) {
+	capabilities := client.ServerCapabilities()
+
+	if supportsProvider(capabilities.SelectionRangeProvider) && int(rng.Start.Line) < len(lines) {
+		// Start from the first non-blank character, indentation is outside the node
+		start := rng.Start
+		startLine := lines[start.Line]
+		start.Character = uint32(len(startLine) - len(strings.TrimLeft(startLine, " \t")))
+
+		ranges, err := client.SelectionRange(ctx, protocol.SelectionRangeParams{
+			TextDocument: protocol.TextDocumentIdentifier{URI: uri},
+			Positions:    []protocol.Position{start},
+		})
+		if err == nil && len(ranges) > 0 {
+			// The smallest syntactic node that starts where the symbol does and
+			// extends past its last line. Enclosing nodes such as the file start
+			// elsewhere and are not taken for the symbol's block
+			for sr := &ranges[0]; sr != nil; sr = sr.Parent {
+				if sr.Range.End.Line > rng.End.Line && sr.Range.Start == start {
+					return sr.Range.End, true
+				}
+			}
+		}
+	}
+
+	if supportsProvider(capabilities.FoldingRangeProvider) {
+		ranges, err := client.FoldingRange(ctx, protocol.FoldingRangeParams{
+			TextDocument: protocol.TextDocumentIdentifier{URI: uri},
+		})
+		if err == nil {
+			var best *lineSpan
+			for _, fr := range ranges {
+				if fr.StartLine != rng.End.Line {
+					continue
+				}
+				span := foldingRangeSpan(fr, lines)
+				if best == nil || span.End < best.End {
+					best = &span
+				}
+			}
+			if best != nil && best.End > rng.End.Line && int(best.End) < len(lines) {
+				return protocol.Position{Line: best.End, Character: uint32(len(lines[best.End]))}, true
+			}
+		}
+	}
+
+	return protocol.Position{}, false
+}
@@ -0,0 +1,168 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFoldingRangeSpan(t *testing.T) {
+	lines := []string{
+		"func main() {",
+		"\tif ok {",
+		"\t\treturn",
+		"\t}",
+		"}",
+		"def f():",
+		"    pass",
+		"",
+	}
+
+	// Brace blocks are extended to include the closing line
+	assert.Equal(t, lineSpan{Start: 1, End: 3}, foldingRangeSpan(protocol.FoldingRange{StartLine: 1, EndLine: 2}, lines))
+	assert.Equal(t, lineSpan{Start: 0, End: 4}, foldingRangeSpan(protocol.FoldingRange{StartLine: 0, EndLine: 3}, lines))
+	// Indentation blocks are left alone
+	assert.Equal(t, lineSpan{Start: 5, End: 6}, foldingRangeSpan(protocol.FoldingRange{StartLine: 5, EndLine: 6}, lines))
+}
+
+func TestAppendBlock(t *testing.T) {
+	var blocks []lineSpan
+	blocks = appendBlock(blocks, lineSpan{Start: 2, End: 2})
+	blocks = appendBlock(blocks, lineSpan{Start: 1, End: 3})
+	blocks = appendBlock(blocks, lineSpan{Start: 1, End: 3})
+	blocks = appendBlock(blocks, lineSpan{Start: 0, End: 4})
+
+	assert.Equal(t, []lineSpan{{Start: 1, End: 3}, {Start: 0, End: 4}}, blocks)
+}
+
+// blocksSource has a map literal and a continued expression whose symbol
+// ranges only cover their first line, as some servers report them.
+const blocksSource = `package config
+
+var Defaults = map[string]int{
+	"a": 1,
+	"b": 2,
+}
+
+var Limit = 1 +
+	2
+
+func run() {
+	if ok {
+		return
+	}
+}
+`
+
+// blocksSelectionRanges maps the line of a requested position in blocksSource
+// to its selection ranges, innermost first.
+var blocksSelectionRanges = map[uint32][]protocol.Range{
+	2:  {lineRange(2, 0, 2, 3), lineRange(2, 0, 5, 1), lineRange(0, 0, 15, 0)},
+	7:  {lineRange(7, 0, 7, 3), lineRange(7, 0, 8, 2), lineRange(0, 0, 15, 0)},
+	10: {lineRange(10, 0, 10, 4), lineRange(10, 0, 14, 1), lineRange(0, 0, 15, 0)},
+	12: {lineRange(12, 2, 12, 8), lineRange(11, 1, 13, 2), lineRange(10, 0, 14, 1), lineRange(0, 0, 15, 0)},
+}
+
+var blocksFoldingRanges = []protocol.FoldingRange{
+	{StartLine: 2, EndLine: 4},
+	{StartLine: 10, EndLine: 13},
+	{StartLine: 11, EndLine: 12},
+}
+
+// newBlocksClient returns a client for a server with the given block
+// capabilities that answers with the ranges of blocksSource.
+func newBlocksClient(t *testing.T, selectionRanges, foldingRanges bool) *lsp.Client {
+	capabilities := protocol.ServerCapabilities{}
+	if selectionRanges {
+		capabilities.SelectionRangeProvider = &protocol.Or_ServerCapabilities_selectionRangeProvider{Value: true}
+	}
+	if foldingRanges {
+		capabilities.FoldingRangeProvider = &protocol.Or_ServerCapabilities_foldingRangeProvider{Value: true}
+	}
+
+	client, _ := newFakeClient(t, capabilities, map[string]fakeHandler{
+		"textDocument/documentSymbol": func(json.RawMessage) (any, error) {
+			return []protocol.DocumentSymbol{
+				{Name: "Defaults", Kind: protocol.Variable, Range: lineRange(2, 4, 2, 31), SelectionRange: lineRange(2, 4, 2, 12)},
+				{Name: "Limit", Kind: protocol.Variable, Range: lineRange(7, 4, 7, 15), SelectionRange: lineRange(7, 4, 7, 9)},
+				{Name: "run", Kind: protocol.Function, Range: lineRange(10, 0, 14, 1), SelectionRange: lineRange(10, 5, 10, 8)},
+			}, nil
+		},
+		"textDocument/selectionRange": func(raw json.RawMessage) (any, error) {
+			var params protocol.SelectionRangeParams
+			if err := json.Unmarshal(raw, &params); err != nil {
+				return nil, err
+			}
+			var parent *protocol.SelectionRange
+			ranges := blocksSelectionRanges[params.Positions[0].Line]
+			for i := len(ranges) - 1; i >= 0; i-- {
+				parent = &protocol.SelectionRange{Range: ranges[i], Parent: parent}
+			}
+			if parent == nil {
+				return []protocol.SelectionRange{}, nil
+			}
+			return []protocol.SelectionRange{*parent}, nil
+		},
+		"textDocument/foldingRange": func(json.RawMessage) (any, error) {
+			return blocksFoldingRanges, nil
+		},
+	})
+	return client
+}
+
+func TestEnclosingBlocks(t *testing.T) {
+	path := writeTestFile(t, "config.go", blocksSource)
+	lines := strings.Split(blocksSource, "\n")
+	position := protocol.TextDocumentPositionParams{
+		TextDocument: protocol.TextDocumentIdentifier{URI: protocol.DocumentUri("file://" + path)},
+		Position:     protocol.Position{Line: 12, Character: 2},
+	}
+
+	tests := []struct {
+		name            string
+		selectionRanges bool
+		foldingRanges   bool
+		wantBlocks      []lineSpan
+		wantSource      string
+		wantErr         string
+	}{
+		{
+			name:            "selection ranges",
+			selectionRanges: true,
+			foldingRanges:   true,
+			wantBlocks:      []lineSpan{{Start: 11, End: 13}, {Start: 10, End: 14}, {Start: 0, End: 15}},
+			wantSource:      "selection ranges",
+		},
+		{
+			name:          "folding ranges",
+			foldingRanges: true,
+			wantBlocks:    []lineSpan{{Start: 11, End: 13}, {Start: 10, End: 14}},
+			wantSource:    "folding ranges",
+		},
+		{
+			name:    "neither",
+			wantErr: "supports neither selection ranges nor folding ranges",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newBlocksClient(t, tt.selectionRanges, tt.foldingRanges)
+
+			blocks, source, err := enclosingBlocks(context.Background(), client, position, lines)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantBlocks, blocks)
+			assert.Equal(t, tt.wantSource, source)
+		})
+	}
+}
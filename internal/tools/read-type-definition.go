@@ -0,0 +1,58 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// TypeDefinitionResult is the result of read_type_definition.
+type TypeDefinitionResult struct {
+	// Symbol is the name the type was looked up by, if any
+	Symbol      string       `json:"symbol,omitempty"`
+	Position    Location     `json:"position"`
+	Definitions []Definition `json:"definitions"`
+
+	target          string
+	showLineNumbers bool
+}
+
+func (r *TypeDefinitionResult) Text() string {
+	if len(r.Definitions) == 0 {
+		return fmt.Sprintf("No type definition found for %s", r.target)
+	}
+
+	return strings.Join(formatDefinitions(r.Definitions, r.showLineNumbers), "\n")
+}
+
+// ReadTypeDefinition returns the full code of the type of the symbol at the given
+// position or with the given name, e.g. the struct behind a variable.
+func ReadTypeDefinition(ctx context.Context, client *lsp.Client, filePath string, line, column int, symbolName string, showLineNumbers bool) (*TypeDefinitionResult, error) {
+	position, err := resolvePosition(ctx, client, filePath, line, column, symbolName)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := client.TypeDefinition(ctx, protocol.TypeDefinitionParams{
+		TextDocumentPositionParams: position,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get type definition: %w", err)
+	}
+
+	locations, err := result.Locations()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse type definition: %w", err)
+	}
+
+	return &TypeDefinitionResult{
+		Symbol:          symbolName,
+		Position:        newPosition(position.TextDocument.URI, position.Position),
+		Definitions:     locationDefinitions(ctx, client, locations),
+		target:          describePosition(position, symbolName),
+		showLineNumbers: showLineNumbers,
+	}, nil
+}
@@ -0,0 +1,225 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// InlayHintsResult is the result of read_with_inlay_hints.
+type InlayHintsResult struct {
+	Path      string `json:"path"`
+	StartLine int    `json:"startLine"`
+	EndLine   int    `json:"endLine"`
+	// Content is the source of the line range without the hints
+	Content string      `json:"content"`
+	Hints   []InlayHint `json:"hints"`
+
+	lines        []string
+	hints        []protocol.InlayHint
+	showTooltips bool
+}
+
+// InlayHint is an inferred type, parameter name or similar annotation shown
+// inline in the code.
+type InlayHint struct {
+	Location Location `json:"location"`
+	Label    string   `json:"label"`
+	// Kind is "type", "parameter" or omitted
+	Kind    string `json:"kind,omitempty"`
+	Tooltip string `json:"tooltip,omitempty"`
+}
+
+func (r *InlayHintsResult) Text() string {
+	byLine := make(map[int][]protocol.InlayHint)
+	for _, hint := range r.hints {
+		byLine[int(hint.Position.Line)] = append(byLine[int(hint.Position.Line)], hint)
+	}
+
+	padding := len(strconv.Itoa(r.EndLine))
+
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("%s (lines %d-%d) with %d inlay hints:\n", r.Path, r.StartLine, r.EndLine, len(r.hints)))
+	output.WriteString(strings.Repeat("=", 80) + "\n")
+
+	for lineIdx := r.StartLine - 1; lineIdx < r.EndLine; lineIdx++ {
+		lineHints := byLine[lineIdx]
+		lineNum := strconv.Itoa(lineIdx + 1)
+		output.WriteString(fmt.Sprintf("%s%s|%s\n",
+			strings.Repeat(" ", padding-len(lineNum)), lineNum, renderInlayHints(r.lines[lineIdx], lineHints)))
+
+		if !r.showTooltips {
+			continue
+		}
+		for _, hint := range lineHints {
+			if tooltip := inlayHintTooltip(hint); tooltip != "" {
+				output.WriteString(fmt.Sprintf("%s  «%s»: %s\n",
+					strings.Repeat(" ", padding), inlayHintLabel(hint), strings.ReplaceAll(tooltip, "\n", " ")))
+			}
+		}
+	}
+
+	return output.String()
+}
+
+// ReadWithInlayHints returns the given 1-based line range of a file with the
+// server's inlay hints (inferred types, parameter names, ...) rendered inline
+// between « and ». With showTooltips, hints are resolved and their tooltips
+// listed below the line they belong to.
+func ReadWithInlayHints(ctx context.Context, client *lsp.Client, filePath string, startLine, endLine int, showTooltips bool) (*InlayHintsResult, error) {
+	absFilePath, err := filepath.Abs(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("could not get absolute path for '%s': %w", filePath, err)
+	}
+	filePath = absFilePath
+
+	err = client.OpenFile(ctx, filePath)
+	if err != nil {
+		return nil, fmt.Errorf("could not open file '%s': %w", filePath, err)
+	}
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	lines := strings.Split(strings.ReplaceAll(string(content), "\r\n", "\n"), "\n")
+	if len(lines) > 1 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	if startLine < 1 {
+		startLine = 1
+	}
+	if endLine < startLine || endLine > len(lines) {
+		endLine = len(lines)
+	}
+	if startLine > endLine {
+		return nil, fmt.Errorf("start line %d is beyond the end of the file (%d lines)", startLine, len(lines))
+	}
+
+	uri := protocol.DocumentUri("file://" + filePath)
+	hints, err := client.InlayHint(ctx, protocol.InlayHintParams{
+		TextDocument: protocol.TextDocumentIdentifier{URI: uri},
+		Range: protocol.Range{
+			Start: protocol.Position{Line: uint32(startLine - 1)},
+			End:   protocol.Position{Line: uint32(endLine - 1), Character: uint32(len(lines[endLine-1]))},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get inlay hints: %w", err)
+	}
+
+	if showTooltips && supportsInlayHintResolve(client) {
+		for i, hint := range hints {
+			if hint.Data == nil {
+				continue
+			}
+			resolved, err := client.Resolve(ctx, hint)
+			if err != nil {
+				log.Printf("Error resolving inlay hint at %d:%d: %v\n", hint.Position.Line+1, hint.Position.Character+1, err)
+				continue
+			}
+			hints[i] = resolved
+		}
+	}
+
+	result := &InlayHintsResult{
+		Path:         filePath,
+		StartLine:    startLine,
+		EndLine:      endLine,
+		Content:      strings.Join(lines[startLine-1:endLine], "\n"),
+		Hints:        []InlayHint{},
+		lines:        lines,
+		hints:        hints,
+		showTooltips: showTooltips,
+	}
+	for _, hint := range hints {
+		item := InlayHint{
+			Location: newPosition(uri, hint.Position),
+			Label:    inlayHintLabel(hint),
+			Kind:     inlayHintKindToString(hint.Kind),
+		}
+		if showTooltips {
+			item.Tooltip = inlayHintTooltip(hint)
+		}
+		result.Hints = append(result.Hints, item)
+	}
+
+	return result, nil
+}
+
+// inlayHintKindToString returns "type", "parameter" or "" for hints without a kind.
+func inlayHintKindToString(kind protocol.InlayHintKind) string {
+	switch kind {
+	case protocol.Type:
+		return "type"
+	case protocol.Parameter:
+		return "parameter"
+	}
+	return ""
+}
+
+// renderInlayHints inserts the label of each hint into line at the hint's
+// position.
+func renderInlayHints(line string, hints []protocol.InlayHint) string {
+	sorted := make([]protocol.InlayHint, len(hints))
+	copy(sorted, hints)
+	// Insert from the end of the line so earlier positions stay valid
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Position.Character > sorted[j].Position.Character
+	})
+
+	for _, hint := range sorted {
+		pos := min(int(hint.Position.Character), len(line))
+		label := "«" + inlayHintLabel(hint) + "»"
+		if hint.PaddingLeft {
+			label = " " + label
+		}
+		if hint.PaddingRight {
+			label += " "
+		}
+		line = line[:pos] + label + line[pos:]
+	}
+	return line
+}
+
+// inlayHintLabel joins the parts of a hint's label.
+func inlayHintLabel(hint protocol.InlayHint) string {
+	var label strings.Builder
+	for _, part := range hint.Label {
+		label.WriteString(part.Value)
+	}
+	return strings.TrimSpace(label.String())
+}
+
+// inlayHintTooltip returns the tooltip of a hint, or of its label parts.
+func inlayHintTooltip(hint protocol.InlayHint) string {
+	if hint.Tooltip != nil {
+		if tooltip := renderDocumentation(hint.Tooltip.Value); tooltip != "" {
+			return tooltip
+		}
+	}
+	var tooltips []string
+	for _, part := range hint.Label {
+		if part.Tooltip != nil {
+			if tooltip := renderDocumentation(part.Tooltip.Value); tooltip != "" {
+				tooltips = append(tooltips, tooltip)
+			}
+		}
+	}
+	return strings.Join(tooltips, " ")
+}
+
+// supportsInlayHintResolve reports whether the server can resolve inlay hints.
+func supportsInlayHintResolve(client *lsp.Client) bool {
+	options, ok := client.ServerCapabilities().InlayHintProvider.(map[string]interface{})
+	return ok && options["resolveProvider"] == true
+}
@@ -0,0 +1,30 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderInlayHints(t *testing.T) {
+	hint := func(character uint32, label string, left, right bool) protocol.InlayHint {
+		return protocol.InlayHint{
+			Position:     protocol.Position{Character: character},
+			Label:        []protocol.InlayHintLabelPart{{Value: label}},
+			PaddingLeft:  left,
+			PaddingRight: right,
+		}
+	}
+
+	line := "\tx, err := parse(data, true)"
+	hints := []protocol.InlayHint{
+		hint(2, "int", true, false),
+		hint(17, "input:", false, true),
+		hint(23, "strict:", false, true),
+		hint(7, "error", true, false),
+	}
+
+	assert.Equal(t, "\tx «int», err «error» := parse(«input:» data, «strict:» true)", renderInlayHints(line, hints))
+	assert.Equal(t, line, renderInlayHints(line, nil))
+}
@@ -10,7 +10,7 @@ import (
 
 	"github.com/isaacphi/mcp-language-server/internal/lsp"
 	"github.com/isaacphi/mcp-language-server/internal/protocol"
-	// "github.com/isaacphi/mcp-language-server/internal/utilities" // Removed unused import
+	"github.com/isaacphi/mcp-language-server/internal/utilities"
 )
 
 // RenameSymbolTool defines the MCP tool for renaming symbols using LSP.
@@ -20,17 +20,63 @@ type RenameSymbolTool struct {
 
 // RenameSymbolArgs defines the arguments for the rename_symbol tool.
 type RenameSymbolArgs struct {
-	FilePath  string `json:"filePath"`  // Required: Path to the file containing the symbol.
-	Line      int    `json:"line"`      // Required: 0-based line number of the symbol.
-	Character int    `json:"character"` // Required: 0-based character offset of the symbol.
-	NewName   string `json:"newName"`   // Required: The new name for the symbol.
+	FilePath   string `json:"filePath"`   // Path to the file containing the symbol. Required unless symbolName is set.
+	Line       int    `json:"line"`       // 0-based line number of the symbol. Ignored if symbolName is set.
+	Character  int    `json:"character"`  // 0-based character offset of the symbol. Ignored if symbolName is set.
+	SymbolName string `json:"symbolName"` // Optional: Name of the symbol, optionally qualified (e.g. MyType.MyMethod).
+	NewName    string `json:"newName"`    // Required: The new name for the symbol.
+	DryRun     bool   `json:"dryRun"`     // Optional: Return diffs without writing to disk.
 }
 
-// RenameSymbolResult defines the result structure (delegating to apply_text_edit format).
+// RenameSymbolResult is the result of rename_symbol.
 type RenameSymbolResult struct {
-	Changes map[string][]protocol.TextEdit `json:"changes"`
+	Changes map[string][]Edit `json:"changes"`         // Text edits keyed by file path.
+	Files   []string          `json:"files"`           // Files created, changed, renamed or deleted.
+	Applied bool              `json:"applied"`         // Whether the edit was written to disk.
+	Diffs   map[string]string `json:"diffs,omitempty"` // Unified diff per file, only for dry runs.
 }
 
+// Edit replaces the text at Location with NewText.
+type Edit struct {
+	Location Location `json:"location"`
+	NewText  string   `json:"newText"`
+}
+
+func (r *RenameSymbolResult) Text() string {
+	if len(r.Files) == 0 {
+		return "No changes: the language server returned no edits for the rename"
+	}
+
+	edits := 0
+	for _, fileEdits := range r.Changes {
+		edits += len(fileEdits)
+	}
+
+	var output strings.Builder
+	if r.Applied {
+		output.WriteString(fmt.Sprintf("Renamed symbol: %d edit(s) in %d file(s)\n", edits, len(r.Files)))
+	} else {
+		output.WriteString(fmt.Sprintf("Rename preview: %d edit(s) in %d file(s), nothing was written to disk\n", edits, len(r.Files)))
+	}
+	output.WriteString(strings.Repeat("=", 80) + "\n")
+
+	for _, path := range r.Files {
+		output.WriteString(path + "\n")
+		for _, edit := range r.Changes[path] {
+			output.WriteString(fmt.Sprintf("  %d:%d-%d:%d -> %s\n",
+				edit.Location.Line,
+				edit.Location.Column,
+				edit.Location.EndLine,
+				edit.Location.EndColumn,
+				edit.NewText))
+		}
+		if diff := r.Diffs[path]; diff != "" {
+			output.WriteString(strings.TrimRight(diff, "\n") + "\n")
+		}
+	}
+
+	return output.String()
+}
 
 // Name returns the name of the tool.
 func (t *RenameSymbolTool) Name() string {
@@ -39,7 +85,7 @@ func (t *RenameSymbolTool) Name() string {
 
 // Description returns the description of the tool.
 func (t *RenameSymbolTool) Description() string {
-	return "Renames a symbol across the workspace using the Language Server Protocol."
+	return "Renames a symbol across the workspace using the Language Server Protocol and writes the changes to disk, or returns a unified diff per file when dryRun is set."
 }
 
 // Schema returns the JSON schema for the tool's arguments and result.
@@ -47,12 +93,14 @@ func (t *RenameSymbolTool) Schema() string {
 	argsSchema := `{
 		"type": "object",
 		"properties": {
-			"filePath": {"type": "string", "description": "Path to the file containing the symbol."},
-			"line": {"type": "integer", "description": "0-based line number of the symbol."},
-			"character": {"type": "integer", "description": "0-based character offset of the symbol."},
-			"newName": {"type": "string", "description": "The new name for the symbol."}
+			"filePath": {"type": "string", "description": "Path to the file containing the symbol. Required unless symbolName is set."},
+			"line": {"type": "integer", "description": "0-based line number of the symbol. Ignored if symbolName is set."},
+			"character": {"type": "integer", "description": "0-based character offset of the symbol. Ignored if symbolName is set."},
+			"symbolName": {"type": "string", "description": "Name of the symbol to rename, optionally qualified with its container (e.g. MyType.MyMethod)."},
+			"newName": {"type": "string", "description": "The new name for the symbol."},
+			"dryRun": {"type": "boolean", "description": "Return a unified diff per file instead of writing the changes to disk."}
 		},
-		"required": ["filePath", "line", "character", "newName"]
+		"required": ["newName"]
 	}`
 	resultSchema := `{
 		"type": "object",
@@ -64,66 +112,98 @@ func (t *RenameSymbolTool) Schema() string {
 					"items": {
 						"type": "object",
 						"properties": {
-							"range": {
+							"location": {
 								"type": "object",
 								"properties": {
-									"start": {"$ref": "#/definitions/position"},
-									"end": {"$ref": "#/definitions/position"}
+									"path": {"type": "string"},
+									"line": {"type": "integer"},
+									"column": {"type": "integer"},
+									"endLine": {"type": "integer"},
+									"endColumn": {"type": "integer"}
 								},
-								"required": ["start", "end"]
+								"required": ["path", "line", "column"]
 							},
 							"newText": {"type": "string"}
 						},
-						"required": ["range", "newText"]
+						"required": ["location", "newText"]
 					}
 				}
-			}
-		},
-		"definitions": {
-			"position": {
-				"type": "object",
-				"properties": {
-					"line": {"type": "integer"},
-					"character": {"type": "integer"}
-				},
-				"required": ["line", "character"]
-			}
+			},
+			"files": {"type": "array", "items": {"type": "string"}},
+			"applied": {"type": "boolean"},
+			"diffs": {"type": "object", "additionalProperties": {"type": "string"}}
 		}
 	}`
 	return fmt.Sprintf(`{"name": "%s", "description": "%s", "input_schema": %s, "output_schema": %s}`, t.Name(), t.Description(), argsSchema, resultSchema)
 }
 
-// Execute performs the rename operation.
+// Execute performs the rename operation and returns the result as JSON.
 func (t *RenameSymbolTool) Execute(ctx context.Context, argsJSON json.RawMessage) (json.RawMessage, error) {
 	var args RenameSymbolArgs
 	if err := json.Unmarshal(argsJSON, &args); err != nil {
 		return nil, fmt.Errorf("invalid arguments: %w", err)
 	}
 
-	// Corrected: Use filepath.Abs to ensure absolute path
-	absPath, err := filepath.Abs(args.FilePath)
+	result, err := t.Rename(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+
+	resultJSON, err := json.Marshal(result)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get absolute path for %s: %w", args.FilePath, err)
+		return nil, fmt.Errorf("failed to marshal rename result: %w", err)
 	}
 
-	// Ensure the file is open in the LSP client
-	if !t.Client.IsFileOpen(absPath) {
-		if err := t.Client.OpenFile(ctx, absPath); err != nil {
-			// Log warning but continue, server might handle it
-			fmt.Fprintf(os.Stderr, "Warning: failed to open file %s before rename: %v\n", absPath, err)
+	return resultJSON, nil
+}
+
+// Rename performs the rename operation.
+func (t *RenameSymbolTool) Rename(ctx context.Context, args RenameSymbolArgs) (*RenameSymbolResult, error) {
+	var position protocol.TextDocumentPositionParams
+	if args.SymbolName != "" {
+		var err error
+		position, err = resolveSymbol(ctx, t.Client, args.SymbolName, args.FilePath)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		if args.FilePath == "" {
+			return nil, fmt.Errorf("either filePath with line/character or symbolName is required")
+		}
+
+		// Corrected: Use filepath.Abs to ensure absolute path
+		absPath, err := filepath.Abs(args.FilePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get absolute path for %s: %w", args.FilePath, err)
+		}
+
+		// Ensure the file is open in the LSP client
+		if !t.Client.IsFileOpen(absPath) {
+			if err := t.Client.OpenFile(ctx, absPath); err != nil {
+				// Log warning but continue, server might handle it
+				fmt.Fprintf(os.Stderr, "Warning: failed to open file %s before rename: %v\n", absPath, err)
+			}
+		}
+
+		position = protocol.TextDocumentPositionParams{
+			TextDocument: protocol.TextDocumentIdentifier{
+				URI: protocol.DocumentUri("file://" + absPath),
+			},
+			Position: protocol.Position{
+				Line:      uint32(args.Line),
+				Character: uint32(args.Character),
+			},
 		}
 	}
 
+	if err := t.prepareRename(ctx, position); err != nil {
+		return nil, err
+	}
 
 	params := protocol.RenameParams{
-		TextDocument: protocol.TextDocumentIdentifier{
-			URI: protocol.DocumentUri("file://" + absPath),
-		},
-		Position: protocol.Position{
-			Line:      uint32(args.Line),
-			Character: uint32(args.Character),
-		},
-		NewName: args.NewName,
+		TextDocument: position.TextDocument,
+		Position:     position.Position,
+		NewName:      args.NewName,
 	}
 
 	workspaceEdit, err := t.Client.RequestRename(ctx, params)
@@ -132,72 +212,83 @@ func (t *RenameSymbolTool) Execute(ctx context.Context, argsJSON json.RawMessage
 	}
 
 	if workspaceEdit == nil {
-		result := RenameSymbolResult{Changes: make(map[string][]protocol.TextEdit)}
-		return json.Marshal(result)
+		return &RenameSymbolResult{
+			Changes: make(map[string][]Edit),
+			Files:   []string{},
+		}, nil
 	}
 
-	// Convert WorkspaceEdit.Changes (map[protocol.DocumentUri][]protocol.TextEdit)
-	convertedChanges := make(map[string][]protocol.TextEdit)
-	if workspaceEdit.Changes != nil {
-		for uri, edits := range workspaceEdit.Changes {
-			// Use TrimPrefix to convert file URI to path for the map key
-			filePathKey := strings.TrimPrefix(string(uri), "file://")
-			convertedChanges[filePathKey] = edits
-		}
+	result := &RenameSymbolResult{
+		Changes: renameChanges(*workspaceEdit),
+		Files:   workspaceEditPaths(*workspaceEdit),
 	}
 
-
-	// Handle DocumentChanges as well
-	if len(workspaceEdit.DocumentChanges) > 0 {
-		for _, docChange := range workspaceEdit.DocumentChanges {
-			// Corrected: Check if TextDocumentEdit field is not nil
-			if docChange.TextDocumentEdit != nil {
-				textDocEdit := docChange.TextDocumentEdit
-				// Corrected: Cast protocol.DocumentUri to string and remove "file://" prefix
-				filePath := strings.TrimPrefix(string(textDocEdit.TextDocument.URI), "file://")
-
-				// Convert edits from Or_TextDocumentEdit_edits_Elem to protocol.TextEdit
-				actualEdits := make([]protocol.TextEdit, 0, len(textDocEdit.Edits))
-				for _, editUnion := range textDocEdit.Edits {
-					// Corrected: Use AsTextEdit method from the union type
-					if te, err := editUnion.AsTextEdit(); err == nil {
-						actualEdits = append(actualEdits, te)
-					} else {
-						// Handle AnnotatedTextEdit or SnippetTextEdit if necessary, or log warning
-						fmt.Fprintf(os.Stderr, "Warning: Skipping non-plain TextEdit in rename result: %v\n", err)
-					}
-				}
-
-				// Append edits, potentially overwriting if the same file exists in Changes map
-				convertedChanges[filePath] = append(convertedChanges[filePath], actualEdits...)
-			} else {
-				// Handle other types like CreateFile, RenameFile, DeleteFile if necessary
-				// For rename, we only care about TextDocumentEdit
-				// Use specific fields like CreateFile, RenameFile, DeleteFile to check type
-				kind := ""
-				if docChange.CreateFile != nil { kind = "CreateFile" }
-				if docChange.RenameFile != nil { kind = "RenameFile" }
-				if docChange.DeleteFile != nil { kind = "DeleteFile" }
-				if kind != "" {
-					fmt.Fprintf(os.Stderr, "Warning: Unsupported document change type '%s' received in rename result.\n", kind)
-				} else {
-					fmt.Fprintf(os.Stderr, "Warning: Unknown document change type received in rename result.\n")
-				}
-			}
+	if args.DryRun {
+		diffs, err := utilities.PreviewWorkspaceEdit(*workspaceEdit)
+		if err != nil {
+			return nil, fmt.Errorf("failed to preview rename: %w", err)
+		}
+		result.Diffs = diffs
+	} else {
+		if err := utilities.ApplyWorkspaceEdit(*workspaceEdit); err != nil {
+			return nil, fmt.Errorf("failed to apply rename: %w", err)
 		}
+		notifyWorkspaceEdit(ctx, t.Client, *workspaceEdit)
+		result.Applied = true
 	}
 
-	result := RenameSymbolResult{
-		Changes: convertedChanges,
+	return result, nil
+}
+
+// prepareRename asks the server whether the symbol at position can be renamed,
+// if the server supports textDocument/prepareRename.
+func (t *RenameSymbolTool) prepareRename(ctx context.Context, position protocol.TextDocumentPositionParams) error {
+	options, ok := t.Client.ServerCapabilities().RenameProvider.(map[string]interface{})
+	if !ok || options["prepareProvider"] != true {
+		return nil
 	}
 
-	resultJSON, err := json.Marshal(result)
+	result, err := t.Client.PrepareRename(ctx, protocol.PrepareRenameParams{
+		TextDocumentPositionParams: position,
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal rename result: %w", err)
+		return fmt.Errorf("cannot rename symbol at line %d, character %d: %w",
+			position.Position.Line, position.Position.Character, err)
 	}
-
-	return resultJSON, nil
+	if result.Value == nil {
+		return fmt.Errorf("cannot rename symbol at line %d, character %d: no renameable symbol at this position",
+			position.Position.Line, position.Position.Character)
+	}
+	return nil
 }
 
-// Removed the compile-time check as Tool interface might not be explicitly defined/needed in this structure
-// var _ Tool = (*RenameSymbolTool)(nil)
+// renameChanges flattens the text edits of a WorkspaceEdit into a map keyed by
+// file path.
+func renameChanges(workspaceEdit protocol.WorkspaceEdit) map[string][]Edit {
+	changes := make(map[string][]Edit)
+	for uri, edits := range workspaceEdit.Changes {
+		// Use TrimPrefix to convert file URI to path for the map key
+		filePathKey := strings.TrimPrefix(string(uri), "file://")
+		for _, te := range edits {
+			changes[filePathKey] = append(changes[filePathKey], Edit{Location: newLocation(uri, te.Range), NewText: te.NewText})
+		}
+	}
+
+	for _, docChange := range workspaceEdit.DocumentChanges {
+		// File operations are applied as well but have no text edits to report
+		if docChange.TextDocumentEdit == nil {
+			continue
+		}
+		textDocEdit := docChange.TextDocumentEdit
+		filePath := strings.TrimPrefix(string(textDocEdit.TextDocument.URI), "file://")
+
+		for _, editUnion := range textDocEdit.Edits {
+			if te, err := editUnion.AsTextEdit(); err == nil {
+				changes[filePath] = append(changes[filePath], Edit{Location: newLocation(textDocEdit.TextDocument.URI, te.Range), NewText: te.NewText})
+			} else {
+				fmt.Fprintf(os.Stderr, "Warning: Skipping non-plain TextEdit in rename result: %v\n", err)
+			}
+		}
+	}
+	return changes
+}
@@ -0,0 +1,129 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchWorkspaceSymbols(t *testing.T) {
+	symbol := func(name string, kind protocol.SymbolKind, container string, line uint32) protocol.WorkspaceSymbolResult {
+		return &protocol.SymbolInformation{
+			Name:          name,
+			Kind:          kind,
+			ContainerName: container,
+			Location: protocol.Location{
+				URI:   "file:///tmp/a.go",
+				Range: protocol.Range{Start: protocol.Position{Line: line}},
+			},
+		}
+	}
+
+	results := []protocol.WorkspaceSymbolResult{
+		symbol("Client.Close", protocol.Method, "lsp", 1),
+		symbol("Close", protocol.Method, "Server", 2),
+		symbol("Closer", protocol.Interface, "io", 3),
+		symbol("Close", protocol.Function, "", 4),
+		symbol("Close", protocol.Function, "", 4), // duplicate location
+	}
+
+	names := func(symbols []protocol.WorkspaceSymbolResult) []string {
+		var out []string
+		for _, s := range symbols {
+			out = append(out, formatSymbolCandidate(s))
+		}
+		return out
+	}
+
+	assert.Len(t, matchWorkspaceSymbols(results, "Close"), 3, "bare name matches methods and functions, not fuzzy matches")
+	assert.Equal(t, []string{"Client.Close (Method) in lsp /tmp/a.go:2:1"}, names(matchWorkspaceSymbols(results, "Client.Close")))
+	assert.Equal(t, []string{"Close (Method) in Server /tmp/a.go:3:1"}, names(matchWorkspaceSymbols(results, "Server.Close")))
+	assert.Empty(t, matchWorkspaceSymbols(results, "Missing.Close"))
+}
+
+func TestIdentifierPosition(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "a.ts")
+	require.NoError(t, os.WriteFile(path, []byte("export function getUserName() {}\nconst getUser = 1\n"), 0644))
+
+	declaration := protocol.Range{
+		Start: protocol.Position{Line: 0, Character: 0},
+		End:   protocol.Position{Line: 0, Character: 32},
+	}
+	assert.Equal(t, protocol.Position{Line: 0, Character: 16}, identifierPosition(path, declaration, "getUserName"))
+
+	// Partial matches are skipped
+	whole := protocol.Range{
+		Start: protocol.Position{Line: 0, Character: 0},
+		End:   protocol.Position{Line: 1, Character: 17},
+	}
+	assert.Equal(t, protocol.Position{Line: 1, Character: 6}, identifierPosition(path, whole, "getUser"))
+
+	// Falls back to the start of the range
+	assert.Equal(t, declaration.Start, identifierPosition(path, declaration, "missing"))
+}
+
+func TestRenameDryRun(t *testing.T) {
+	path := writeTestFile(t, "user.go", "package user\n\nfunc getUser() {}\n\nvar f = getUser\n")
+	uri := protocol.DocumentUri("file://" + path)
+	client, _ := newFakeClient(t, protocol.ServerCapabilities{}, map[string]fakeHandler{
+		"textDocument/rename": func(json.RawMessage) (any, error) {
+			return protocol.WorkspaceEdit{
+				Changes: map[protocol.DocumentUri][]protocol.TextEdit{
+					uri: {
+						{Range: lineRange(2, 5, 2, 12), NewText: "fetchUser"},
+						{Range: lineRange(4, 8, 4, 15), NewText: "fetchUser"},
+					},
+				},
+			}, nil
+		},
+	})
+
+	tool := RenameSymbolTool{Client: client}
+	result, err := tool.Rename(context.Background(), RenameSymbolArgs{FilePath: path, Line: 2, Character: 5, NewName: "fetchUser", DryRun: true})
+	require.NoError(t, err)
+
+	// Ranges are reported 1-based like every other location
+	assert.Equal(t, map[string][]Edit{
+		path: {
+			{Location: Location{Path: path, Line: 3, Column: 6, EndLine: 3, EndColumn: 13}, NewText: "fetchUser"},
+			{Location: Location{Path: path, Line: 5, Column: 9, EndLine: 5, EndColumn: 16}, NewText: "fetchUser"},
+		},
+	}, result.Changes)
+	assert.Equal(t, []string{path}, result.Files)
+	assert.False(t, result.Applied)
+	require.Contains(t, result.Diffs, path)
+
+	// Nothing is written on a dry run
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "getUser")
+
+	text := result.Text()
+	assert.True(t, strings.HasPrefix(text, "Rename preview: 2 edit(s) in 1 file(s), nothing was written to disk\n"), text)
+	assert.Contains(t, text, path+"\n  3:6-3:13 -> fetchUser\n  5:9-5:16 -> fetchUser\n")
+	assert.Contains(t, text, "+func fetchUser() {}")
+}
+
+func TestRenameSymbolResultText(t *testing.T) {
+	result := &RenameSymbolResult{
+		Changes: map[string][]Edit{
+			"/ws/a.go": {{Location: Location{Path: "/ws/a.go", Line: 3, Column: 6, EndLine: 3, EndColumn: 13}, NewText: "fetchUser"}},
+		},
+		Files:   []string{"/ws/a.go"},
+		Applied: true,
+	}
+	assert.Equal(t, "Renamed symbol: 1 edit(s) in 1 file(s)\n"+
+		"================================================================================\n"+
+		"/ws/a.go\n"+
+		"  3:6-3:13 -> fetchUser\n", result.Text())
+
+	empty := &RenameSymbolResult{Changes: map[string][]Edit{}, Files: []string{}}
+	assert.Equal(t, "No changes: the language server returned no edits for the rename", empty.Text())
+}
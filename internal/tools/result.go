@@ -0,0 +1,138 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// Output formats of tool results.
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Result is the outcome of a tool. In text mode Text is returned to the client;
+// in JSON mode the result itself is marshalled, so the exported fields and
+// their json tags are the documented schema of each tool's output.
+type Result interface {
+	Text() string
+}
+
+// Render formats a result in the given output format. An empty format is text.
+func Render(result Result, format string) (string, error) {
+	switch format {
+	case "", FormatText:
+		return result.Text(), nil
+	case FormatJSON:
+		data, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal result: %w", err)
+		}
+		return string(data), nil
+	}
+	return "", fmt.Errorf("invalid format: %s. Must be 'text' or 'json'", format)
+}
+
+// ValidateFormat checks that format is a known output format or empty.
+func ValidateFormat(format string) error {
+	if format != "" && format != FormatText && format != FormatJSON {
+		return fmt.Errorf("invalid format: %s. Must be 'text' or 'json'", format)
+	}
+	return nil
+}
+
+// Location is a position or range in a file. Lines and columns are 1-based;
+// the end is omitted for plain positions.
+type Location struct {
+	Path      string `json:"path"`
+	Line      int    `json:"line"`
+	Column    int    `json:"column"`
+	EndLine   int    `json:"endLine,omitempty"`
+	EndColumn int    `json:"endColumn,omitempty"`
+}
+
+// newLocation converts a protocol range in the document at uri to a Location.
+func newLocation(uri protocol.DocumentUri, rng protocol.Range) Location {
+	return Location{
+		Path:      strings.TrimPrefix(string(uri), "file://"),
+		Line:      int(rng.Start.Line) + 1,
+		Column:    int(rng.Start.Character) + 1,
+		EndLine:   int(rng.End.Line) + 1,
+		EndColumn: int(rng.End.Character) + 1,
+	}
+}
+
+// newPosition converts a protocol position in the document at uri to a Location.
+func newPosition(uri protocol.DocumentUri, position protocol.Position) Location {
+	return Location{
+		Path:   strings.TrimPrefix(string(uri), "file://"),
+		Line:   int(position.Line) + 1,
+		Column: int(position.Character) + 1,
+	}
+}
+
+// String formats the start of the location as path:line:column.
+func (l Location) String() string {
+	return fmt.Sprintf("%s:%d:%d", l.Path, l.Line, l.Column)
+}
+
+// Diagnostic is an error, warning or hint reported by the language server.
+type Diagnostic struct {
+	Location Location `json:"location"`
+	// Severity is "error", "warning", "info", "hint" or "unknown"
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+	Source   string `json:"source,omitempty"`
+	Code     string `json:"code,omitempty"`
+	// Snippet is the source code the diagnostic refers to, if requested
+	Snippet string `json:"snippet,omitempty"`
+	// SnippetLine is the 1-based line Snippet starts on if it is not Location.Line
+	SnippetLine int `json:"snippetLine,omitempty"`
+}
+
+// newDiagnostic converts a protocol diagnostic in the document at uri.
+func newDiagnostic(uri protocol.DocumentUri, diag protocol.Diagnostic) Diagnostic {
+	code := ""
+	if diag.Code != nil {
+		code = fmt.Sprint(diag.Code)
+	}
+	return Diagnostic{
+		Location: newLocation(uri, diag.Range),
+		Severity: strings.ToLower(getSeverityString(diag.Severity)),
+		Message:  diag.Message,
+		Source:   diag.Source,
+		Code:     code,
+	}
+}
+
+// newDiagnostics converts the protocol diagnostics of a document. The result is
+// never nil so that it marshals as an empty array.
+func newDiagnostics(uri protocol.DocumentUri, diags []protocol.Diagnostic) []Diagnostic {
+	result := make([]Diagnostic, 0, len(diags))
+	for _, diag := range diags {
+		result = append(result, newDiagnostic(uri, diag))
+	}
+	return result
+}
+
+// Symbol is a named code element such as a function, type or variable, with
+// its source code where the tool returns it.
+type Symbol struct {
+	Name      string   `json:"name"`
+	Kind      string   `json:"kind,omitempty"`
+	Detail    string   `json:"detail,omitempty"`
+	Container string   `json:"container,omitempty"`
+	Location  Location `json:"location"`
+	Snippet   string   `json:"snippet,omitempty"`
+	// Language is the language of the server that found the symbol
+	Language string `json:"language,omitempty"`
+}
+
+// Definition is the full source code of a declaration.
+type Definition struct {
+	Location Location `json:"location"`
+	Snippet  string   `json:"snippet"`
+}
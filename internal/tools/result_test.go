@@ -0,0 +1,49 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRender(t *testing.T) {
+	result := &FormatResult{Path: "/ws/main.go"}
+
+	text, err := Render(result, "")
+	require.NoError(t, err)
+	assert.Equal(t, "/ws/main.go is already formatted", text)
+
+	text, err = Render(result, FormatText)
+	require.NoError(t, err)
+	assert.Equal(t, "/ws/main.go is already formatted", text)
+
+	text, err = Render(result, FormatJSON)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"path": "/ws/main.go", "changed": false}`, text)
+
+	_, err = Render(result, "xml")
+	assert.Error(t, err)
+}
+
+func TestRenderEmptyList(t *testing.T) {
+	result := &DiagnosticsResult{Path: "/ws/main.go", Diagnostics: newDiagnostics("file:///ws/main.go", nil)}
+
+	text, err := Render(result, FormatJSON)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"path": "/ws/main.go", "diagnostics": []}`, text)
+}
+
+func TestNewLocation(t *testing.T) {
+	rng := protocol.Range{
+		Start: protocol.Position{Line: 4, Character: 0},
+		End:   protocol.Position{Line: 6, Character: 1},
+	}
+
+	assert.Equal(t, Location{Path: "/ws/main.go", Line: 5, Column: 1, EndLine: 7, EndColumn: 2},
+		newLocation("file:///ws/main.go", rng))
+	assert.Equal(t, Location{Path: "/ws/main.go", Line: 5, Column: 1},
+		newPosition("file:///ws/main.go", rng.Start))
+	assert.Equal(t, "/ws/main.go:5:1", newPosition("file:///ws/main.go", rng.Start).String())
+}
@@ -0,0 +1,261 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// Semantic token modes accepted by GetSemanticTokens.
+const (
+	TokensAll        = "tokens"
+	TokensDeprecated = "deprecated"
+)
+
+// semanticToken is a decoded entry of a semantic token stream with 0-based
+// absolute positions.
+type semanticToken struct {
+	Line      uint32
+	StartChar uint32
+	Length    uint32
+	Type      string
+	Modifiers []string
+}
+
+// SemanticTokensResult is the result of semantic_tokens.
+type SemanticTokensResult struct {
+	Path string `json:"path"`
+	// Mode is "tokens" or "deprecated"
+	Mode      string          `json:"mode"`
+	StartLine int             `json:"startLine"`
+	EndLine   int             `json:"endLine"`
+	Tokens    []SemanticToken `json:"tokens"`
+}
+
+// SemanticToken is a classified token of the source code.
+type SemanticToken struct {
+	Location Location `json:"location"`
+	Text     string   `json:"text"`
+	// Type and Modifiers are named after the server's legend, e.g. "function" and "deprecated"
+	Type      string   `json:"type"`
+	Modifiers []string `json:"modifiers,omitempty"`
+	// Line is the source line of the token, in deprecated mode only
+	Line string `json:"line,omitempty"`
+}
+
+func (r *SemanticTokensResult) Text() string {
+	var output strings.Builder
+	if r.Mode == TokensDeprecated {
+		output.WriteString(fmt.Sprintf("Deprecated symbol uses in %s (lines %d-%d): %d\n", r.Path, r.StartLine, r.EndLine, len(r.Tokens)))
+	} else {
+		output.WriteString(fmt.Sprintf("Semantic tokens in %s (lines %d-%d): %d\n", r.Path, r.StartLine, r.EndLine, len(r.Tokens)))
+	}
+	output.WriteString(strings.Repeat("=", 80) + "\n")
+
+	for _, token := range r.Tokens {
+		mods := ""
+		if len(token.Modifiers) > 0 {
+			mods = " [" + strings.Join(token.Modifiers, ", ") + "]"
+		}
+
+		if r.Mode == TokensDeprecated {
+			output.WriteString(fmt.Sprintf("%d:%d %s (%s)%s\n    %s\n",
+				token.Location.Line, token.Location.Column, token.Text, token.Type, mods, token.Line))
+		} else {
+			output.WriteString(fmt.Sprintf("%d:%d %s (%s)%s\n",
+				token.Location.Line, token.Location.Column, token.Text, token.Type, mods))
+		}
+	}
+
+	return output.String()
+}
+
+// GetSemanticTokens classifies the tokens of a file, or of a 1-based line range
+// of it, using the server's semantic tokens. In TokensAll mode every token
+// matching the given types and modifiers is listed; in TokensDeprecated mode only
+// uses of deprecated symbols are listed, with their line.
+func GetSemanticTokens(ctx context.Context, client *lsp.Client, filePath string, startLine, endLine int, mode string, types, modifiers []string) (*SemanticTokensResult, error) {
+	if mode == "" {
+		mode = TokensAll
+	}
+	if mode != TokensAll && mode != TokensDeprecated {
+		return nil, fmt.Errorf("invalid mode: %s. Must be 'tokens' or 'deprecated'", mode)
+	}
+
+	legend, supportsRange, err := semanticTokensLegend(client)
+	if err != nil {
+		return nil, err
+	}
+
+	absFilePath, err := filepath.Abs(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("could not get absolute path for '%s': %w", filePath, err)
+	}
+	filePath = absFilePath
+
+	err = client.OpenFile(ctx, filePath)
+	if err != nil {
+		return nil, fmt.Errorf("could not open file '%s': %w", filePath, err)
+	}
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	lines := strings.Split(strings.ReplaceAll(string(content), "\r\n", "\n"), "\n")
+
+	if startLine < 1 {
+		startLine = 1
+	}
+	if endLine < startLine || endLine > len(lines) {
+		endLine = len(lines)
+	}
+
+	uri := protocol.DocumentUri("file://" + filePath)
+	var tokens protocol.SemanticTokens
+	if supportsRange && (startLine > 1 || endLine < len(lines)) {
+		tokens, err = client.SemanticTokensRange(ctx, protocol.SemanticTokensRangeParams{
+			TextDocument: protocol.TextDocumentIdentifier{URI: uri},
+			Range: protocol.Range{
+				Start: protocol.Position{Line: uint32(startLine - 1)},
+				End:   protocol.Position{Line: uint32(endLine - 1), Character: uint32(len(lines[endLine-1]))},
+			},
+		})
+	} else {
+		tokens, err = client.SemanticTokensFull(ctx, protocol.SemanticTokensParams{
+			TextDocument: protocol.TextDocumentIdentifier{URI: uri},
+		})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get semantic tokens: %w", err)
+	}
+
+	if mode == TokensDeprecated {
+		modifiers = []string{"deprecated"}
+	}
+
+	result := &SemanticTokensResult{
+		Path:      filePath,
+		Mode:      mode,
+		StartLine: startLine,
+		EndLine:   endLine,
+		Tokens:    []SemanticToken{},
+	}
+	for _, token := range decodeSemanticTokens(tokens.Data, legend) {
+		// Full results are filtered down to the requested lines
+		if int(token.Line) < startLine-1 || int(token.Line) > endLine-1 {
+			continue
+		}
+		if len(types) > 0 && !slices.Contains(types, token.Type) {
+			continue
+		}
+		if !containsAll(token.Modifiers, modifiers) {
+			continue
+		}
+
+		line := ""
+		if int(token.Line) < len(lines) {
+			line = lines[token.Line]
+		}
+		item := SemanticToken{
+			Location: Location{
+				Path:      filePath,
+				Line:      int(token.Line) + 1,
+				Column:    int(token.StartChar) + 1,
+				EndLine:   int(token.Line) + 1,
+				EndColumn: int(token.StartChar+token.Length) + 1,
+			},
+			Text:      tokenText(line, token),
+			Type:      token.Type,
+			Modifiers: token.Modifiers,
+		}
+		if mode == TokensDeprecated {
+			item.Line = strings.TrimSpace(line)
+		}
+		result.Tokens = append(result.Tokens, item)
+	}
+
+	return result, nil
+}
+
+// decodeSemanticTokens decodes the relative, five-integer-per-token encoding of
+// the LSP specification into absolute tokens named after the legend.
+func decodeSemanticTokens(data []uint32, legend protocol.SemanticTokensLegend) []semanticToken {
+	var tokens []semanticToken
+	var line, char uint32
+	for i := 0; i+4 < len(data); i += 5 {
+		deltaLine, deltaStart := data[i], data[i+1]
+		if deltaLine > 0 {
+			line += deltaLine
+			char = deltaStart
+		} else {
+			char += deltaStart
+		}
+
+		token := semanticToken{
+			Line:      line,
+			StartChar: char,
+			Length:    data[i+2],
+			Type:      "unknown",
+		}
+		if typeIdx := int(data[i+3]); typeIdx < len(legend.TokenTypes) {
+			token.Type = legend.TokenTypes[typeIdx]
+		}
+		for bit, modifier := range legend.TokenModifiers {
+			if data[i+4]&(1<<uint(bit)) != 0 {
+				token.Modifiers = append(token.Modifiers, modifier)
+			}
+		}
+		tokens = append(tokens, token)
+	}
+	return tokens
+}
+
+// semanticTokensLegend returns the legend the server declared in its initialize
+// response and whether it supports range requests.
+func semanticTokensLegend(client *lsp.Client) (protocol.SemanticTokensLegend, bool, error) {
+	provider := client.ServerCapabilities().SemanticTokensProvider
+	if provider == nil {
+		return protocol.SemanticTokensLegend{}, false, fmt.Errorf("the language server does not support semantic tokens")
+	}
+
+	// The provider is decoded generically, so round-trip it into the typed options
+	data, err := json.Marshal(provider)
+	if err != nil {
+		return protocol.SemanticTokensLegend{}, false, fmt.Errorf("failed to read semantic tokens options: %w", err)
+	}
+	var options struct {
+		Legend protocol.SemanticTokensLegend `json:"legend"`
+		Range  interface{}                   `json:"range"`
+	}
+	if err := json.Unmarshal(data, &options); err != nil {
+		return protocol.SemanticTokensLegend{}, false, fmt.Errorf("failed to read semantic tokens options: %w", err)
+	}
+
+	supportsRange := options.Range != nil && options.Range != false
+	return options.Legend, supportsRange, nil
+}
+
+// tokenText returns the source text of a token on its line.
+func tokenText(line string, token semanticToken) string {
+	start := min(int(token.StartChar), len(line))
+	end := min(start+int(token.Length), len(line))
+	return line[start:end]
+}
+
+// containsAll reports whether values contains every element of required.
+func containsAll(values, required []string) bool {
+	for _, r := range required {
+		if !slices.Contains(values, r) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,33 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeSemanticTokens(t *testing.T) {
+	legend := protocol.SemanticTokensLegend{
+		TokenTypes:     []string{"function", "parameter", "variable"},
+		TokenModifiers: []string{"declaration", "readonly", "deprecated"},
+	}
+
+	data := []uint32{
+		// line 2, col 5: function declaration
+		2, 5, 3, 0, 1,
+		// same line, 4 further: readonly parameter
+		0, 4, 1, 1, 2,
+		// line 5, col 1: deprecated variable
+		3, 1, 6, 2, 4,
+		// unknown type index
+		0, 8, 2, 9, 0,
+	}
+
+	assert.Equal(t, []semanticToken{
+		{Line: 2, StartChar: 5, Length: 3, Type: "function", Modifiers: []string{"declaration"}},
+		{Line: 2, StartChar: 9, Length: 1, Type: "parameter", Modifiers: []string{"readonly"}},
+		{Line: 5, StartChar: 1, Length: 6, Type: "variable", Modifiers: []string{"deprecated"}},
+		{Line: 5, StartChar: 9, Length: 2, Type: "unknown"},
+	}, decodeSemanticTokens(data, legend))
+}
@@ -0,0 +1,86 @@
+package tools
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// States of a language server
+const (
+	ServerNotStarted = "not started"
+	ServerStarting   = "starting"
+	ServerRunning    = "running"
+	ServerRestarting = "restarting"
+	ServerFailed     = "failed"
+)
+
+// ServerStatusResult is the result of language_server_status.
+type ServerStatusResult struct {
+	Servers []ServerStatus `json:"servers"`
+}
+
+// ServerStatus describes the state of one configured language server.
+type ServerStatus struct {
+	Language string `json:"language"`
+	Command  string `json:"command"`
+	// Startup is "eager" or "lazy"
+	Startup string `json:"startup"`
+	// State is "not started", "starting", "running", "restarting" or "failed"
+	State string `json:"state"`
+	PID   int    `json:"pid,omitempty"`
+	// OpenFiles is the number of files open in the server
+	OpenFiles int `json:"openFiles"`
+	// Restarts counts how often the server was restarted after it exited
+	Restarts int `json:"restarts"`
+	// LastExit is why the server last exited, if it did
+	LastExit     string     `json:"lastExit,omitempty"`
+	LastExitTime *time.Time `json:"lastExitTime,omitempty"`
+	// LastRestartTime is when the server was last restarted successfully
+	LastRestartTime *time.Time `json:"lastRestartTime,omitempty"`
+	// NextRestartTime is when the next restart is attempted while restarting
+	NextRestartTime *time.Time `json:"nextRestartTime,omitempty"`
+	// Error is why the server failed to start
+	Error string `json:"error,omitempty"`
+}
+
+func (r *ServerStatusResult) Text() string {
+	if len(r.Servers) == 0 {
+		return "No language servers configured"
+	}
+
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("Language servers: %d\n", len(r.Servers)))
+	output.WriteString(strings.Repeat("=", 80) + "\n")
+
+	for _, server := range r.Servers {
+		state := server.State
+		if server.PID != 0 {
+			state = fmt.Sprintf("%s (pid %d)", state, server.PID)
+		}
+		output.WriteString(fmt.Sprintf("%s: %s\n", server.Language, state))
+		output.WriteString(fmt.Sprintf("    Command: %s, startup: %s, open files: %d, restarts: %d\n",
+			server.Command, server.Startup, server.OpenFiles, server.Restarts))
+		if server.LastExit != "" {
+			output.WriteString(fmt.Sprintf("    Last exit: %s at %s\n", server.LastExit, formatStatusTime(server.LastExitTime)))
+		}
+		if server.LastRestartTime != nil {
+			output.WriteString(fmt.Sprintf("    Last restart: %s\n", formatStatusTime(server.LastRestartTime)))
+		}
+		if server.NextRestartTime != nil {
+			output.WriteString(fmt.Sprintf("    Next restart attempt: %s\n", formatStatusTime(server.NextRestartTime)))
+		}
+		if server.Error != "" {
+			output.WriteString(fmt.Sprintf("    Error: %s\n", server.Error))
+		}
+	}
+
+	return output.String()
+}
+
+func formatStatusTime(t *time.Time) string {
+	if t == nil {
+		return "unknown"
+	}
+	return t.Format(time.RFC3339)
+}
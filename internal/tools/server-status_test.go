@@ -0,0 +1,40 @@
+package tools
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServerStatusText(t *testing.T) {
+	exited := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	restarted := exited.Add(2 * time.Second)
+	result := &ServerStatusResult{Servers: []ServerStatus{
+		{
+			Language:        "typescript",
+			Command:         "typescript-language-server",
+			Startup:         "eager",
+			State:           ServerRunning,
+			PID:             4242,
+			OpenFiles:       3,
+			Restarts:        1,
+			LastExit:        "language server exited: connection closed",
+			LastExitTime:    &exited,
+			LastRestartTime: &restarted,
+		},
+		{Language: "python", Command: "pyright-langserver", Startup: "lazy", State: ServerNotStarted},
+	}}
+
+	assert.Equal(t, "Language servers: 2\n"+
+		"================================================================================\n"+
+		"typescript: running (pid 4242)\n"+
+		"    Command: typescript-language-server, startup: eager, open files: 3, restarts: 1\n"+
+		"    Last exit: language server exited: connection closed at 2025-03-01T12:00:00Z\n"+
+		"    Last restart: 2025-03-01T12:00:02Z\n"+
+		"python: not started\n"+
+		"    Command: pyright-langserver, startup: lazy, open files: 0, restarts: 0\n",
+		result.Text())
+
+	assert.Equal(t, "No language servers configured", (&ServerStatusResult{}).Text())
+}
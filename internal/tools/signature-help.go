@@ -0,0 +1,169 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// SignatureHelpResult is the result of signature_help.
+type SignatureHelpResult struct {
+	Position   Location    `json:"position"`
+	Signatures []Signature `json:"signatures"`
+}
+
+// Signature is one overload of a function or method.
+type Signature struct {
+	Label string `json:"label"`
+	// Active marks the signature the server considers the best match for the call
+	Active        bool        `json:"active,omitempty"`
+	Parameters    []Parameter `json:"parameters"`
+	Documentation string      `json:"documentation,omitempty"`
+}
+
+// Parameter is a parameter of a signature.
+type Parameter struct {
+	Label string `json:"label"`
+	// Active marks the parameter at the position in the active signature
+	Active        bool   `json:"active,omitempty"`
+	Documentation string `json:"documentation,omitempty"`
+}
+
+func (r *SignatureHelpResult) Text() string {
+	if len(r.Signatures) == 0 {
+		return fmt.Sprintf("No signature help available at %s. The position must be inside the parentheses of a call.", r.Position)
+	}
+
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("Signature help for %s\n", r.Position))
+	output.WriteString(strings.Repeat("=", 80) + "\n")
+
+	for i, sig := range r.Signatures {
+		active := ""
+		if sig.Active {
+			active = " (active)"
+		}
+		output.WriteString(fmt.Sprintf("\n[%d/%d] %s%s\n", i+1, len(r.Signatures), sig.Label, active))
+
+		if len(sig.Parameters) > 0 {
+			output.WriteString("  Parameters:\n")
+		}
+		for _, param := range sig.Parameters {
+			marker := "  "
+			if param.Active {
+				marker = "> "
+			}
+			output.WriteString(fmt.Sprintf("  %s%s\n", marker, param.Label))
+			if param.Documentation != "" {
+				output.WriteString(indentLines(param.Documentation, "      ") + "\n")
+			}
+		}
+
+		if sig.Documentation != "" {
+			output.WriteString("  Documentation:\n")
+			output.WriteString(indentLines(sig.Documentation, "    ") + "\n")
+		}
+	}
+
+	return output.String()
+}
+
+// GetSignatureHelp returns every overload signature of the call expression at the
+// given position, marking the active signature and parameter and including the
+// documentation of each parameter.
+func GetSignatureHelp(ctx context.Context, client *lsp.Client, filePath string, line, column int) (*SignatureHelpResult, error) {
+	if line < 1 {
+		return nil, fmt.Errorf("line is required")
+	}
+
+	position, err := resolvePosition(ctx, client, filePath, line, column, "")
+	if err != nil {
+		return nil, err
+	}
+
+	// The response is decoded twice because protocol.SignatureHelp cannot tell
+	// an absent activeParameter on a signature from parameter 0
+	var raw json.RawMessage
+	err = client.Call(ctx, "textDocument/signatureHelp", protocol.SignatureHelpParams{
+		TextDocumentPositionParams: position,
+		Context: &protocol.SignatureHelpContext{
+			TriggerKind: protocol.SigInvoked,
+		},
+	}, &raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get signature help: %w", err)
+	}
+	var help protocol.SignatureHelp
+	var activeParameters struct {
+		Signatures []struct {
+			ActiveParameter *uint32 `json:"activeParameter"`
+		} `json:"signatures"`
+	}
+	if len(raw) > 0 && string(raw) != "null" {
+		if err := json.Unmarshal(raw, &help); err != nil {
+			return nil, fmt.Errorf("failed to parse signature help: %w", err)
+		}
+		if err := json.Unmarshal(raw, &activeParameters); err != nil {
+			return nil, fmt.Errorf("failed to parse signature help: %w", err)
+		}
+	}
+
+	result := &SignatureHelpResult{
+		Position:   newPosition(position.TextDocument.URI, position.Position),
+		Signatures: []Signature{},
+	}
+	for i, sig := range help.Signatures {
+		signature := Signature{
+			Label:      sig.Label,
+			Active:     uint32(i) == help.ActiveSignature,
+			Parameters: []Parameter{},
+		}
+
+		// The signature's own active parameter takes precedence over the global one
+		activeParameter := help.ActiveParameter
+		if own := activeParameters.Signatures[i].ActiveParameter; own != nil {
+			activeParameter = *own
+		}
+
+		for j, param := range sig.Parameters {
+			parameter := Parameter{
+				Label:  parameterLabel(sig.Label, param),
+				Active: signature.Active && uint32(j) == activeParameter,
+			}
+			if param.Documentation != nil {
+				parameter.Documentation = renderDocumentation(param.Documentation.Value)
+			}
+			signature.Parameters = append(signature.Parameters, parameter)
+		}
+
+		if sig.Documentation != nil {
+			signature.Documentation = renderDocumentation(sig.Documentation.Value)
+		}
+		result.Signatures = append(result.Signatures, signature)
+	}
+
+	return result, nil
+}
+
+// parameterLabel returns the label of a parameter, which servers send either as
+// a string or as offsets into the signature label.
+func parameterLabel(signatureLabel string, param protocol.ParameterInformation) string {
+	switch v := param.Label.Value.(type) {
+	case string:
+		return v
+	case protocol.Tuple_ParameterInformation_label_Item1:
+		if v.Fld0 <= v.Fld1 && int(v.Fld1) <= len(signatureLabel) {
+			return signatureLabel[v.Fld0:v.Fld1]
+		}
+	}
+	return "?"
+}
+
+// indentLines prefixes every line of text with indent.
+func indentLines(text string, indent string) string {
+	return indent + strings.ReplaceAll(text, "\n", "\n"+indent)
+}
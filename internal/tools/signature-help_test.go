@@ -0,0 +1,67 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetSignatureHelpActiveParameter(t *testing.T) {
+	path := writeTestFile(t, "main.go", "package main\n\nfunc main() { fmt.Printf(\"%d\", 1) }\n")
+
+	tests := []struct {
+		name     string
+		response string
+		want     [][]bool
+	}{
+		{
+			name:     "top-level active parameter",
+			response: `{"signatures":[{"label":"f(a, b)","parameters":[{"label":"a"},{"label":"b"}]}],"activeParameter":1}`,
+			want:     [][]bool{{false, true}},
+		},
+		{
+			name:     "signature active parameter zero overrides top-level",
+			response: `{"signatures":[{"label":"f(a, b)","parameters":[{"label":"a"},{"label":"b"}],"activeParameter":0}],"activeParameter":1}`,
+			want:     [][]bool{{true, false}},
+		},
+		{
+			name: "only the active signature",
+			response: `{"signatures":[` +
+				`{"label":"f(a)","parameters":[{"label":"a"}]},` +
+				`{"label":"f(a, b)","parameters":[{"label":"a"},{"label":"b"}],"activeParameter":1}` +
+				`],"activeSignature":1}`,
+			want: [][]bool{{false}, {false, true}},
+		},
+		{
+			name:     "no signature help",
+			response: `null`,
+			want:     [][]bool{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, _ := newFakeClient(t, protocol.ServerCapabilities{}, map[string]fakeHandler{
+				"textDocument/signatureHelp": func(json.RawMessage) (any, error) {
+					return json.RawMessage(tt.response), nil
+				},
+			})
+
+			result, err := GetSignatureHelp(context.Background(), client, path, 3, 30)
+			require.NoError(t, err)
+
+			active := [][]bool{}
+			for _, sig := range result.Signatures {
+				var params []bool
+				for _, param := range sig.Parameters {
+					params = append(params, param.Active)
+				}
+				active = append(active, params)
+			}
+			assert.Equal(t, tt.want, active)
+		})
+	}
+}
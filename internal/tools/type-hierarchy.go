@@ -0,0 +1,191 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// Type hierarchy directions accepted by GetTypeHierarchy.
+const (
+	TypesSuper = "supertypes"
+	TypesSub   = "subtypes"
+	TypesBoth  = "both"
+)
+
+const defaultTypeHierarchyDepth = 3
+
+// TypeHierarchyResult is the result of type_hierarchy.
+type TypeHierarchyResult struct {
+	Position Location `json:"position"`
+	// Direction is "supertypes", "subtypes" or "both"
+	Direction string `json:"direction"`
+	MaxDepth  int    `json:"maxDepth"`
+	// Items are the types at the position, usually one
+	Items []TypeHierarchy `json:"items"`
+}
+
+// TypeHierarchy is the type tree of one type.
+type TypeHierarchy struct {
+	Item HierarchyItem `json:"item"`
+	// Supertypes and Subtypes are omitted if not requested
+	Supertypes []TypeNode `json:"supertypes,omitempty"`
+	Subtypes   []TypeNode `json:"subtypes,omitempty"`
+}
+
+// TypeNode is a supertype or subtype in a type tree.
+type TypeNode struct {
+	Item HierarchyItem `json:"item"`
+	// AlreadyShown marks items that appear earlier in the tree; they are not expanded again
+	AlreadyShown bool `json:"alreadyShown,omitempty"`
+	// Types are the supertypes, or subtypes, of Item
+	Types []TypeNode `json:"types,omitempty"`
+}
+
+func (r *TypeHierarchyResult) Text() string {
+	if len(r.Items) == 0 {
+		return fmt.Sprintf("No type hierarchy item found at %s", r.Position)
+	}
+
+	var output strings.Builder
+	for _, hierarchy := range r.Items {
+		output.WriteString(strings.Repeat("=", 80) + "\n")
+		output.WriteString(fmt.Sprintf("Type hierarchy for %s\n", hierarchy.Item))
+		output.WriteString(strings.Repeat("=", 80) + "\n")
+
+		if r.Direction == TypesSuper || r.Direction == TypesBoth {
+			output.WriteString(fmt.Sprintf("\nSupertypes (max depth %d):\n", r.MaxDepth))
+			if len(hierarchy.Supertypes) == 0 {
+				output.WriteString(fmt.Sprintf("  (no %s found)\n", TypesSuper))
+			}
+			writeTypeNodes(&output, hierarchy.Supertypes, 1)
+		}
+
+		if r.Direction == TypesSub || r.Direction == TypesBoth {
+			output.WriteString(fmt.Sprintf("\nSubtypes (max depth %d):\n", r.MaxDepth))
+			if len(hierarchy.Subtypes) == 0 {
+				output.WriteString(fmt.Sprintf("  (no %s found)\n", TypesSub))
+			}
+			writeTypeNodes(&output, hierarchy.Subtypes, 1)
+		}
+		output.WriteString("\n")
+	}
+
+	return output.String()
+}
+
+// writeTypeNodes writes each node of the tree followed by its own types.
+func writeTypeNodes(output *strings.Builder, nodes []TypeNode, depth int) {
+	indent := strings.Repeat("  ", depth)
+	for _, node := range nodes {
+		suffix := ""
+		if node.AlreadyShown {
+			suffix = " (already shown)"
+		}
+		output.WriteString(fmt.Sprintf("%s- %s%s\n", indent, node.Item, suffix))
+		writeTypeNodes(output, node.Types, depth+1)
+	}
+}
+
+// GetTypeHierarchy returns the supertypes and/or subtypes of the type at the given
+// position or with the given name, up to maxDepth levels deep.
+func GetTypeHierarchy(ctx context.Context, client *lsp.Client, filePath string, line, column int, symbolName string, direction string, maxDepth int) (*TypeHierarchyResult, error) {
+	if direction == "" {
+		direction = TypesBoth
+	}
+	if direction != TypesSuper && direction != TypesSub && direction != TypesBoth {
+		return nil, fmt.Errorf("invalid direction: %s. Must be 'supertypes', 'subtypes' or 'both'", direction)
+	}
+	if maxDepth < 1 {
+		maxDepth = defaultTypeHierarchyDepth
+	}
+
+	position, err := resolvePosition(ctx, client, filePath, line, column, symbolName)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := client.PrepareTypeHierarchy(ctx, protocol.TypeHierarchyPrepareParams{
+		TextDocumentPositionParams: position,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare type hierarchy: %w", err)
+	}
+
+	result := &TypeHierarchyResult{
+		Position:  newPosition(position.TextDocument.URI, position.Position),
+		Direction: direction,
+		MaxDepth:  maxDepth,
+		Items:     []TypeHierarchy{},
+	}
+	for _, item := range items {
+		hierarchy := TypeHierarchy{Item: newTypeHierarchyItem(item)}
+
+		if direction == TypesSuper || direction == TypesBoth {
+			visited := map[string]bool{typeHierarchyItemKey(item): true}
+			hierarchy.Supertypes, err = relatedTypes(ctx, client, item, TypesSuper, 1, maxDepth, visited)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if direction == TypesSub || direction == TypesBoth {
+			visited := map[string]bool{typeHierarchyItemKey(item): true}
+			hierarchy.Subtypes, err = relatedTypes(ctx, client, item, TypesSub, 1, maxDepth, visited)
+			if err != nil {
+				return nil, err
+			}
+		}
+		result.Items = append(result.Items, hierarchy)
+	}
+
+	return result, nil
+}
+
+// relatedTypes returns the supertypes or subtypes of item, each with its own.
+// Items in visited are returned but not expanded again.
+func relatedTypes(ctx context.Context, client *lsp.Client, item protocol.TypeHierarchyItem, direction string, depth, maxDepth int, visited map[string]bool) ([]TypeNode, error) {
+	var related []protocol.TypeHierarchyItem
+	var err error
+	if direction == TypesSuper {
+		related, err = client.Supertypes(ctx, protocol.TypeHierarchySupertypesParams{Item: item})
+	} else {
+		related, err = client.Subtypes(ctx, protocol.TypeHierarchySubtypesParams{Item: item})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s for %s: %w", direction, item.Name, err)
+	}
+
+	var nodes []TypeNode
+	for _, rel := range related {
+		key := typeHierarchyItemKey(rel)
+		node := TypeNode{Item: newTypeHierarchyItem(rel), AlreadyShown: visited[key]}
+		if !visited[key] && depth < maxDepth {
+			visited[key] = true
+			node.Types, err = relatedTypes(ctx, client, rel, direction, depth+1, maxDepth, visited)
+			if err != nil {
+				return nil, err
+			}
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes, nil
+}
+
+// newTypeHierarchyItem converts a protocol type hierarchy item.
+func newTypeHierarchyItem(item protocol.TypeHierarchyItem) HierarchyItem {
+	return HierarchyItem{
+		Name:     item.Name,
+		Kind:     symbolKindToString(item.Kind),
+		Detail:   item.Detail,
+		Location: newLocation(item.URI, item.SelectionRange),
+	}
+}
+
+// typeHierarchyItemKey identifies an item for cycle detection.
+func typeHierarchyItemKey(item protocol.TypeHierarchyItem) string {
+	return fmt.Sprintf("%s:%d:%d", item.URI, item.SelectionRange.Start.Line, item.SelectionRange.Start.Character)
+}
@@ -0,0 +1,131 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// typeTree flattens type nodes into names indented by depth, with nodes that
+// are not expanded again marked by "*".
+func typeTree(nodes []TypeNode, indent string) []string {
+	var lines []string
+	for _, node := range nodes {
+		line := indent + node.Item.Name
+		if node.AlreadyShown {
+			line += "*"
+		}
+		lines = append(lines, line)
+		lines = append(lines, typeTree(node.Types, indent+"  ")...)
+	}
+	return lines
+}
+
+func TestGetTypeHierarchy(t *testing.T) {
+	path := writeTestFile(t, "pets.go", "package pets\n")
+	uri := protocol.DocumentUri("file://" + path)
+	item := func(name string, kind protocol.SymbolKind, line uint32) protocol.TypeHierarchyItem {
+		rng := protocol.Range{Start: protocol.Position{Line: line, Character: 5}, End: protocol.Position{Line: line, Character: 5 + uint32(len(name))}}
+		return protocol.TypeHierarchyItem{Name: name, Kind: kind, URI: uri, Range: rng, SelectionRange: rng}
+	}
+	items := map[string]protocol.TypeHierarchyItem{
+		"Animal": item("Animal", protocol.Interface, 2),
+		"Pet":    item("Pet", protocol.Interface, 6),
+		"Dog":    item("Dog", protocol.Struct, 10),
+		"Puppy":  item("Puppy", protocol.Struct, 14),
+	}
+	// Pet embeds Animal, Dog implements both and Puppy embeds Dog
+	supertypes := map[string][]string{"Pet": {"Animal"}, "Dog": {"Animal", "Pet"}, "Puppy": {"Dog"}}
+	related := func(names []string) []protocol.TypeHierarchyItem {
+		result := []protocol.TypeHierarchyItem{}
+		for _, name := range names {
+			result = append(result, items[name])
+		}
+		return result
+	}
+
+	client, _ := newFakeClient(t, protocol.ServerCapabilities{}, map[string]fakeHandler{
+		"textDocument/prepareTypeHierarchy": func(json.RawMessage) (any, error) {
+			return related([]string{"Dog"}), nil
+		},
+		"typeHierarchy/supertypes": func(params json.RawMessage) (any, error) {
+			var p protocol.TypeHierarchySupertypesParams
+			require.NoError(t, json.Unmarshal(params, &p))
+			return related(supertypes[p.Item.Name]), nil
+		},
+		"typeHierarchy/subtypes": func(params json.RawMessage) (any, error) {
+			var p protocol.TypeHierarchySubtypesParams
+			require.NoError(t, json.Unmarshal(params, &p))
+			var subtypes []string
+			for _, name := range []string{"Animal", "Pet", "Dog", "Puppy"} {
+				for _, super := range supertypes[name] {
+					if super == p.Item.Name {
+						subtypes = append(subtypes, name)
+					}
+				}
+			}
+			return related(subtypes), nil
+		},
+	})
+
+	tests := []struct {
+		name       string
+		direction  string
+		maxDepth   int
+		supertypes []string
+		subtypes   []string
+	}{
+		{
+			name:       "shared supertypes are shown once",
+			direction:  TypesBoth,
+			maxDepth:   3,
+			supertypes: []string{"Animal", "Pet", "  Animal*"},
+			subtypes:   []string{"Puppy"},
+		},
+		{
+			name:       "depth limit",
+			direction:  TypesSuper,
+			maxDepth:   1,
+			supertypes: []string{"Animal", "Pet"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := GetTypeHierarchy(context.Background(), client, path, 11, 6, "", tt.direction, tt.maxDepth)
+			require.NoError(t, err)
+			require.Len(t, result.Items, 1)
+			assert.Equal(t, "Dog", result.Items[0].Item.Name)
+			assert.Equal(t, tt.supertypes, typeTree(result.Items[0].Supertypes, ""))
+			assert.Equal(t, tt.subtypes, typeTree(result.Items[0].Subtypes, ""))
+		})
+	}
+}
+
+func TestTypeHierarchyResultText(t *testing.T) {
+	dog := HierarchyItem{Name: "Dog", Kind: "Struct", Location: Location{Path: "/ws/pets.go", Line: 11, Column: 6}}
+	animal := HierarchyItem{Name: "Animal", Kind: "Interface", Detail: "pets", Location: Location{Path: "/ws/pets.go", Line: 3, Column: 6}}
+	result := &TypeHierarchyResult{
+		Direction: TypesBoth,
+		MaxDepth:  2,
+		Items: []TypeHierarchy{{
+			Item:       dog,
+			Supertypes: []TypeNode{{Item: animal}, {Item: animal, AlreadyShown: true}},
+		}},
+	}
+
+	assert.Equal(t, "================================================================================\n"+
+		"Type hierarchy for Dog (Struct) /ws/pets.go:11\n"+
+		"================================================================================\n"+
+		"\nSupertypes (max depth 2):\n"+
+		"  - Animal (Interface) [pets] /ws/pets.go:3\n"+
+		"  - Animal (Interface) [pets] /ws/pets.go:3 (already shown)\n"+
+		"\nSubtypes (max depth 2):\n"+
+		"  (no subtypes found)\n\n", result.Text())
+
+	empty := &TypeHierarchyResult{Position: Location{Path: "/ws/pets.go", Line: 1, Column: 1}}
+	assert.Equal(t, "No type hierarchy item found at /ws/pets.go:1:1", empty.Text())
+}
@@ -2,11 +2,16 @@ package tools
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log"
 	"net/url"
 	"os"
+	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/isaacphi/mcp-language-server/internal/lsp"
 	"github.com/isaacphi/mcp-language-server/internal/protocol"
@@ -163,8 +168,15 @@ func GetFullDefinition(ctx context.Context, client *lsp.Client, startLocation pr
 		trimmedLine := strings.TrimSpace(line)
 
 		// In some cases, constant definitions do not include the full body and instead
-		// end with an opening bracket. In this case, parse the file until the closing bracket
-		if len(trimmedLine) > 0 {
+		// end where a block opens. Ask the server where the block ends, or, if it
+		// supports neither selection nor folding ranges, parse the file until the
+		// closing bracket of a line that ends with an opening one
+		capabilities := client.ServerCapabilities()
+		if supportsProvider(capabilities.SelectionRangeProvider) || supportsProvider(capabilities.FoldingRangeProvider) {
+			if end, ok := blockEnd(ctx, client, startLocation.URI, symbolRange, lines); ok {
+				symbolRange.End = end
+			}
+		} else if len(trimmedLine) > 0 {
 			lastChar := trimmedLine[len(trimmedLine)-1]
 			if lastChar == '(' || lastChar == '[' || lastChar == '{' || lastChar == '<' {
 				// Find matching closing bracket
@@ -231,3 +243,390 @@ func addLineNumbers(text string, startLine int) string {
 	}
 	return result.String()
 }
+
+// resolvePosition converts the position arguments shared by the position-based
+// tools into LSP TextDocumentPositionParams. When line is set, the 1-based
+// line and column are used directly. Otherwise symbolName is resolved with
+// resolveSymbol, restricted to filePath if it is set.
+func resolvePosition(ctx context.Context, client *lsp.Client, filePath string, line, column int, symbolName string) (protocol.TextDocumentPositionParams, error) {
+	if filePath == "" {
+		if symbolName == "" {
+			return protocol.TextDocumentPositionParams{}, fmt.Errorf("either filePath with line/column or symbolName is required")
+		}
+		return resolveSymbol(ctx, client, symbolName, "")
+	}
+
+	absFilePath, err := filepath.Abs(filePath)
+	if err != nil {
+		return protocol.TextDocumentPositionParams{}, fmt.Errorf("could not get absolute path for '%s': %w", filePath, err)
+	}
+	filePath = absFilePath
+
+	if err := client.OpenFile(ctx, filePath); err != nil {
+		return protocol.TextDocumentPositionParams{}, fmt.Errorf("could not open file '%s': %w", filePath, err)
+	}
+
+	uri := protocol.DocumentUri("file://" + filePath)
+
+	if line > 0 {
+		if column < 1 {
+			column = 1
+		}
+		return protocol.TextDocumentPositionParams{
+			TextDocument: protocol.TextDocumentIdentifier{URI: uri},
+			Position: protocol.Position{
+				Line:      uint32(line - 1),
+				Character: uint32(column - 1),
+			},
+		}, nil
+	}
+
+	if symbolName == "" {
+		return protocol.TextDocumentPositionParams{}, fmt.Errorf("either line/column or symbolName is required")
+	}
+
+	return resolveSymbol(ctx, client, symbolName, filePath)
+}
+
+// resolveSymbol finds the position of the symbol called symbolName through
+// workspace/symbol, in the same way ReadDefinition does. The name may be
+// qualified with its container (e.g. MyType.MyMethod). If filePath is set, only
+// symbols in that file are considered. It fails with a list of the candidates
+// if the name is ambiguous.
+func resolveSymbol(ctx context.Context, client *lsp.Client, symbolName string, filePath string) (protocol.TextDocumentPositionParams, error) {
+	symbolResult, err := client.Symbol(ctx, protocol.WorkspaceSymbolParams{
+		Query: symbolName,
+	})
+	if err != nil {
+		return protocol.TextDocumentPositionParams{}, fmt.Errorf("Failed to fetch symbol: %v", err)
+	}
+
+	results, err := symbolResult.Results()
+	if err != nil {
+		return protocol.TextDocumentPositionParams{}, fmt.Errorf("Failed to parse results: %v", err)
+	}
+
+	candidates := matchWorkspaceSymbols(results, symbolName)
+	if filePath != "" {
+		absPath, err := filepath.Abs(filePath)
+		if err != nil {
+			return protocol.TextDocumentPositionParams{}, fmt.Errorf("failed to get absolute path for %s: %w", filePath, err)
+		}
+		var inFile []protocol.WorkspaceSymbolResult
+		for _, candidate := range candidates {
+			if strings.TrimPrefix(string(candidate.GetLocation().URI), "file://") == absPath {
+				inFile = append(inFile, candidate)
+			}
+		}
+		candidates = inFile
+	}
+
+	switch len(candidates) {
+	case 0:
+		return protocol.TextDocumentPositionParams{}, fmt.Errorf("symbol '%s' not found", symbolName)
+	case 1:
+	default:
+		var list strings.Builder
+		for _, candidate := range candidates {
+			list.WriteString("\n  - " + formatSymbolCandidate(candidate))
+		}
+		return protocol.TextDocumentPositionParams{}, fmt.Errorf(
+			"symbol '%s' is ambiguous, %d candidates found. Qualify it with its container (e.g. MyType.MyMethod), pass filePath, or give the position instead:%s",
+			symbolName, len(candidates), list.String())
+	}
+
+	loc := candidates[0].GetLocation()
+	path := strings.TrimPrefix(string(loc.URI), "file://")
+	if err := client.OpenFile(ctx, path); err != nil {
+		return protocol.TextDocumentPositionParams{}, fmt.Errorf("could not open file '%s': %w", path, err)
+	}
+
+	name := symbolName
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		name = name[i+1:]
+	}
+
+	return protocol.TextDocumentPositionParams{
+		TextDocument: protocol.TextDocumentIdentifier{URI: loc.URI},
+		Position:     identifierPosition(path, loc.Range, name),
+	}, nil
+}
+
+// identifierPosition returns the position of the first occurrence of name as a
+// whole word within rng. Servers often report the range of the whole
+// declaration, which starts at a keyword or modifier rather than the name.
+// It falls back to the start of rng.
+func identifierPosition(path string, rng protocol.Range, name string) protocol.Position {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return rng.Start
+	}
+	lines := strings.Split(string(content), "\n")
+
+	for lineNum := int(rng.Start.Line); lineNum <= int(rng.End.Line) && lineNum < len(lines); lineNum++ {
+		line := lines[lineNum]
+		offset := 0
+		if lineNum == int(rng.Start.Line) {
+			offset = min(int(rng.Start.Character), len(line))
+		}
+		for {
+			idx := strings.Index(line[offset:], name)
+			if idx < 0 {
+				break
+			}
+			start := offset + idx
+			end := start + len(name)
+			if (start == 0 || !isIdentifierByte(line[start-1])) && (end == len(line) || !isIdentifierByte(line[end])) {
+				return protocol.Position{Line: uint32(lineNum), Character: uint32(start)}
+			}
+			offset = end
+		}
+	}
+	return rng.Start
+}
+
+func isIdentifierByte(b byte) bool {
+	return b == '_' || b == '$' || (b >= '0' && b <= '9') || (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z')
+}
+
+// matchWorkspaceSymbols filters workspace/symbol results, which may be fuzzy
+// matches, down to the symbols called symbolName. A qualified name such as
+// "MyType.MyMethod" matches symbols with that exact name, as reported by gopls,
+// and symbols named "MyMethod" whose container ends with "MyType". Methods
+// reported as "MyType.MyMethod" also match a bare "MyMethod".
+func matchWorkspaceSymbols(results []protocol.WorkspaceSymbolResult, symbolName string) []protocol.WorkspaceSymbolResult {
+	container, name := "", symbolName
+	if i := strings.LastIndex(symbolName, "."); i > 0 && i < len(symbolName)-1 {
+		container, name = symbolName[:i], symbolName[i+1:]
+	}
+
+	var matches []protocol.WorkspaceSymbolResult
+	seen := make(map[string]bool)
+	for _, symbol := range results {
+		info := symbolBaseInfo(symbol)
+		matched := symbol.GetName() == symbolName ||
+			(container != "" && symbol.GetName() == name && strings.HasSuffix(info.ContainerName, container)) ||
+			(container == "" && info.Kind == protocol.Method && strings.HasSuffix(symbol.GetName(), "."+symbolName))
+		if !matched {
+			continue
+		}
+
+		loc := symbol.GetLocation()
+		key := fmt.Sprintf("%s:%d:%d", loc.URI, loc.Range.Start.Line, loc.Range.Start.Character)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		matches = append(matches, symbol)
+	}
+	return matches
+}
+
+// symbolBaseInfo returns the kind and container shared by both workspace symbol
+// result types.
+func symbolBaseInfo(symbol protocol.WorkspaceSymbolResult) protocol.BaseSymbolInformation {
+	switch v := symbol.(type) {
+	case *protocol.SymbolInformation:
+		return protocol.BaseSymbolInformation{
+			Name:          v.Name,
+			Kind:          v.Kind,
+			Tags:          v.Tags,
+			ContainerName: v.ContainerName,
+		}
+	case *protocol.WorkspaceSymbol:
+		return v.BaseSymbolInformation
+	}
+	return protocol.BaseSymbolInformation{Name: symbol.GetName()}
+}
+
+// formatSymbolCandidate formats a workspace symbol as "Name (Kind) in Container
+// path:line:column" for disambiguation messages.
+func formatSymbolCandidate(symbol protocol.WorkspaceSymbolResult) string {
+	info := symbolBaseInfo(symbol)
+	loc := symbol.GetLocation()
+	container := ""
+	if info.ContainerName != "" {
+		container = " in " + info.ContainerName
+	}
+	return fmt.Sprintf("%s (%s)%s %s:%d:%d",
+		symbol.GetName(),
+		symbolKindToString(info.Kind),
+		container,
+		strings.TrimPrefix(string(loc.URI), "file://"),
+		loc.Range.Start.Line+1,
+		loc.Range.Start.Character+1)
+}
+
+// locationDefinitions returns the full code block at each location, as
+// ReadDefinition does for symbols. Duplicate locations are only returned once.
+func locationDefinitions(ctx context.Context, client *lsp.Client, locations []protocol.Location) []Definition {
+	definitions := []Definition{}
+	seen := make(map[string]bool)
+	for _, loc := range locations {
+		key := fmt.Sprintf("%s:%d:%d", loc.URI, loc.Range.Start.Line, loc.Range.Start.Character)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		filePath, err := url.PathUnescape(strings.TrimPrefix(string(loc.URI), "file://"))
+		if err == nil {
+			if err := client.OpenFile(ctx, filePath); err != nil {
+				log.Printf("Error opening %s: %v\n", filePath, err)
+			}
+		}
+
+		definition, fullLoc, err := GetFullDefinition(ctx, client, loc)
+		if err != nil {
+			log.Printf("Error getting definition: %v\n", err)
+			continue
+		}
+
+		definitions = append(definitions, Definition{
+			Location: newLocation(fullLoc.URI, fullLoc.Range),
+			Snippet:  definition,
+		})
+	}
+	return definitions
+}
+
+// formatDefinitions renders definitions in the same layout as ReadDefinition.
+func formatDefinitions(definitions []Definition, showLineNumbers bool) []string {
+	var formatted []string
+	for _, def := range definitions {
+		banner := strings.Repeat("=", 80) + "\n"
+		locationInfo := fmt.Sprintf(
+			"File: %s\n"+
+				"Start Position: Line %d, Column %d\n"+
+				"End Position: Line %d, Column %d\n"+
+				"%s\n",
+			def.Location.Path,
+			def.Location.Line,
+			def.Location.Column,
+			def.Location.EndLine,
+			def.Location.EndColumn,
+			strings.Repeat("=", 80))
+
+		definition := def.Snippet
+		if showLineNumbers {
+			definition = addLineNumbers(definition, def.Location.Line)
+		}
+
+		formatted = append(formatted, banner+locationInfo+definition+"\n")
+	}
+	return formatted
+}
+
+// describePosition names a resolved position for tool output, preferring the
+// symbol name the caller used.
+func describePosition(position protocol.TextDocumentPositionParams, symbolName string) string {
+	location := fmt.Sprintf("%s:%d:%d",
+		strings.TrimPrefix(string(position.TextDocument.URI), "file://"),
+		position.Position.Line+1,
+		position.Position.Character+1)
+	if symbolName != "" {
+		return fmt.Sprintf("%s (%s)", symbolName, location)
+	}
+	return location
+}
+
+// workspaceEditPaths returns the paths of all files touched by a WorkspaceEdit.
+// Paths from Changes come first in sorted order, followed by DocumentChanges in
+// the order the server sent them.
+func workspaceEditPaths(edit protocol.WorkspaceEdit) []string {
+	var paths []string
+	seen := make(map[string]bool)
+	add := func(uri protocol.DocumentUri) {
+		path := strings.TrimPrefix(string(uri), "file://")
+		if !seen[path] {
+			seen[path] = true
+			paths = append(paths, path)
+		}
+	}
+
+	changedURIs := make([]string, 0, len(edit.Changes))
+	for uri := range edit.Changes {
+		changedURIs = append(changedURIs, string(uri))
+	}
+	sort.Strings(changedURIs)
+	for _, uri := range changedURIs {
+		add(protocol.DocumentUri(uri))
+	}
+	for _, change := range edit.DocumentChanges {
+		switch {
+		case change.TextDocumentEdit != nil:
+			add(change.TextDocumentEdit.TextDocument.URI)
+		case change.CreateFile != nil:
+			add(change.CreateFile.URI)
+		case change.RenameFile != nil:
+			add(change.RenameFile.OldURI)
+			add(change.RenameFile.NewURI)
+		case change.DeleteFile != nil:
+			add(change.DeleteFile.URI)
+		}
+	}
+	return paths
+}
+
+// notifyWorkspaceEdit tells the language server about files changed on disk by
+// an applied WorkspaceEdit, so that follow-up requests see the new content.
+// Open files that were renamed or deleted are closed.
+func notifyWorkspaceEdit(ctx context.Context, client *lsp.Client, edit protocol.WorkspaceEdit) {
+	for _, path := range workspaceEditPaths(edit) {
+		if !client.IsFileOpen(path) {
+			continue
+		}
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			if err := client.CloseFile(ctx, path); err != nil {
+				log.Printf("Error closing %s: %v\n", path, err)
+			}
+			continue
+		}
+		if err := client.NotifyChange(ctx, path); err != nil {
+			log.Printf("Error notifying change for %s: %v\n", path, err)
+		}
+	}
+}
+
+// queryLanguages runs query with every client concurrently and returns the
+// results by language. A failing server is logged and skipped as long as
+// another one answers; if all of them fail their errors are returned.
+func queryLanguages[T any](ctx context.Context, clients map[string]*lsp.Client, query func(context.Context, *lsp.Client) (T, error)) (map[string]T, error) {
+	languages := sortedLanguages(clients)
+	results := make([]T, len(languages))
+	errs := make([]error, len(languages))
+	var wg sync.WaitGroup
+	for i, language := range languages {
+		wg.Add(1)
+		go func(i int, client *lsp.Client) {
+			defer wg.Done()
+			results[i], errs[i] = query(ctx, client)
+		}(i, clients[language])
+	}
+	wg.Wait()
+
+	byLanguage := make(map[string]T)
+	var failed []error
+	for i, language := range languages {
+		if errs[i] != nil {
+			log.Printf("Request to the %s language server failed: %v", language, errs[i])
+			failed = append(failed, fmt.Errorf("%s: %w", language, errs[i]))
+			continue
+		}
+		byLanguage[language] = results[i]
+	}
+	if len(failed) > 0 && len(byLanguage) == 0 {
+		return nil, errors.Join(failed...)
+	}
+	return byLanguage, nil
+}
+
+// sortedLanguages returns the keys of a map by language in alphabetical order.
+func sortedLanguages[T any](byLanguage map[string]T) []string {
+	languages := make([]string, 0, len(byLanguage))
+	for language := range byLanguage {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+	return languages
+}
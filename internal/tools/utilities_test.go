@@ -0,0 +1,147 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolvePositionBySymbolName(t *testing.T) {
+	clientPath := writeTestFile(t, "client.go", "package rpc\n\nfunc (c *Client) Call() {}\n")
+	serverPath := writeTestFile(t, "server.go", "package rpc\n\nfunc (s *Server) Call() {}\n")
+	otherPath := writeTestFile(t, "other.go", "package rpc\n")
+	symbols := []protocol.SymbolInformation{
+		{
+			Name: "Call", Kind: protocol.Method, ContainerName: "rpc.Client",
+			Location: protocol.Location{URI: protocol.DocumentUri("file://" + clientPath), Range: lineRange(2, 0, 2, 26)},
+		},
+		{
+			Name: "Call", Kind: protocol.Method, ContainerName: "rpc.Server",
+			Location: protocol.Location{URI: protocol.DocumentUri("file://" + serverPath), Range: lineRange(2, 0, 2, 26)},
+		},
+	}
+	client, _ := newFakeClient(t, protocol.ServerCapabilities{}, map[string]fakeHandler{
+		"workspace/symbol": func(json.RawMessage) (any, error) {
+			return symbols, nil
+		},
+	})
+
+	tests := []struct {
+		name       string
+		filePath   string
+		symbolName string
+		wantPath   string
+		wantErr    []string
+	}{
+		{
+			name:       "ambiguous",
+			symbolName: "Call",
+			wantErr: []string{
+				"symbol 'Call' is ambiguous, 2 candidates found",
+				"Call (Method) in rpc.Client " + clientPath + ":3:1",
+				"Call (Method) in rpc.Server " + serverPath + ":3:1",
+			},
+		},
+		{
+			name:       "qualified with container",
+			symbolName: "Server.Call",
+			wantPath:   serverPath,
+		},
+		{
+			name:       "restricted to file",
+			filePath:   clientPath,
+			symbolName: "Call",
+			wantPath:   clientPath,
+		},
+		{
+			name:       "not in file",
+			filePath:   otherPath,
+			symbolName: "Call",
+			wantErr:    []string{"symbol 'Call' not found"},
+		},
+		{
+			name:       "unknown container",
+			symbolName: "Conn.Call",
+			wantErr:    []string{"symbol 'Conn.Call' not found"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			position, err := resolvePosition(context.Background(), client, tt.filePath, 0, 0, tt.symbolName)
+			if tt.wantErr != nil {
+				require.Error(t, err)
+				for _, want := range tt.wantErr {
+					assert.Contains(t, err.Error(), want)
+				}
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, protocol.DocumentUri("file://"+tt.wantPath), position.TextDocument.URI)
+			// The position is moved from the start of the declaration to the name.
+			assert.Equal(t, protocol.Position{Line: 2, Character: 17}, position.Position)
+		})
+	}
+}
+
+func TestGetHoverInfoAmbiguousSymbol(t *testing.T) {
+	client, server := newFakeClient(t, protocol.ServerCapabilities{}, map[string]fakeHandler{
+		"workspace/symbol": func(json.RawMessage) (any, error) {
+			return []protocol.SymbolInformation{
+				{Name: "Call", Kind: protocol.Method, ContainerName: "Client", Location: protocol.Location{URI: "file:///ws/client.go"}},
+				{Name: "Call", Kind: protocol.Method, ContainerName: "Server", Location: protocol.Location{URI: "file:///ws/server.go"}},
+			}, nil
+		},
+	})
+
+	_, err := GetHoverInfo(context.Background(), client, "", 0, 0, "Call")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "ambiguous")
+	assert.Zero(t, server.requestCount("textDocument/hover"))
+}
+
+func TestGetFullDefinition(t *testing.T) {
+	path := writeTestFile(t, "config.go", blocksSource)
+	uri := protocol.DocumentUri("file://" + path)
+	defaults := "var Defaults = map[string]int{\n\t\"a\": 1,\n\t\"b\": 2,\n}"
+	run := "func run() {\n\tif ok {\n\t\treturn\n\t}\n}"
+
+	tests := []struct {
+		name            string
+		selectionRanges bool
+		foldingRanges   bool
+		line            uint32
+		want            string
+		wantEndLine     uint32
+	}{
+		{name: "scanner extends to the closing bracket", line: 2, want: defaults, wantEndLine: 5},
+		{name: "scanner needs an opening bracket", line: 7, want: "var Limit = 1 +", wantEndLine: 7},
+		{name: "complete symbol", line: 11, want: run, wantEndLine: 14},
+		{name: "selection ranges extend to the block end", selectionRanges: true, line: 2, want: defaults, wantEndLine: 5},
+		{name: "selection ranges without a bracket", selectionRanges: true, line: 7, want: "var Limit = 1 +\n\t2", wantEndLine: 8},
+		{name: "selection ranges keep a complete symbol", selectionRanges: true, line: 11, want: run, wantEndLine: 14},
+		{name: "folding ranges extend to the block end", foldingRanges: true, line: 2, want: defaults, wantEndLine: 5},
+		{name: "folding ranges keep a complete symbol", foldingRanges: true, line: 11, want: run, wantEndLine: 14},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newBlocksClient(t, tt.selectionRanges, tt.foldingRanges)
+
+			definition, loc, err := GetFullDefinition(context.Background(), client, protocol.Location{
+				URI:   uri,
+				Range: lineRange(tt.line, 5, tt.line, 5),
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, definition)
+			assert.Equal(t, tt.wantEndLine, loc.Range.End.Line)
+			assert.Zero(t, loc.Range.Start.Character)
+		})
+	}
+
+	client := newBlocksClient(t, false, false)
+	_, _, err := GetFullDefinition(context.Background(), client, protocol.Location{URI: uri, Range: lineRange(0, 0, 0, 0)})
+	assert.EqualError(t, err, "symbol not found")
+}
@@ -0,0 +1,286 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/isaacphi/mcp-language-server/internal/utilities"
+)
+
+// DiagnosticsFilter selects the diagnostics reported by GetWorkspaceDiagnostics.
+// Empty fields match everything.
+type DiagnosticsFilter struct {
+	// MinSeverity is the least severe level to include: error, warning, info or hint
+	MinSeverity string
+	// PathGlob is matched against workspace-relative and absolute paths, ** matches any number of directories
+	PathGlob string
+	Source   string
+	Code     string
+}
+
+// WorkspaceDiagnosticsResult is the result of get_workspace_diagnostics.
+type WorkspaceDiagnosticsResult struct {
+	// Sources says for each language server where its diagnostics came from
+	Sources []string `json:"sources"`
+	// Counts maps severities to the number of diagnostics in all files
+	Counts map[string]int    `json:"counts"`
+	Files  []FileDiagnostics `json:"files"`
+
+	workspaceDir string
+	summaryOnly  bool
+}
+
+// FileDiagnostics holds the diagnostics of one file.
+type FileDiagnostics struct {
+	Path string `json:"path"`
+	// Counts maps severities to the number of diagnostics in the file
+	Counts map[string]int `json:"counts"`
+	// Diagnostics is omitted for summaries
+	Diagnostics []Diagnostic `json:"diagnostics,omitempty"`
+}
+
+func (r *WorkspaceDiagnosticsResult) Text() string {
+	count := 0
+	for _, c := range r.Counts {
+		count += c
+	}
+	if count == 0 {
+		return fmt.Sprintf("No diagnostics found in the workspace (from %s)", strings.Join(r.Sources, ", "))
+	}
+
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("Diagnostics in the workspace: %d in %d files (%s)\n",
+		count, len(r.Files), formatSeverityCounts(r.Counts)))
+	output.WriteString(fmt.Sprintf("Source: %s\n", strings.Join(r.Sources, ", ")))
+	output.WriteString(strings.Repeat("=", 80) + "\n")
+
+	for _, file := range r.Files {
+		output.WriteString(fmt.Sprintf("%s: %s\n", relativePath(file.Path, r.workspaceDir), formatSeverityCounts(file.Counts)))
+	}
+
+	if r.summaryOnly {
+		return output.String()
+	}
+
+	for _, file := range r.Files {
+		output.WriteString("\n" + strings.Repeat("=", 80) + "\n")
+		output.WriteString(relativePath(file.Path, r.workspaceDir) + "\n")
+		output.WriteString(strings.Repeat("=", 80) + "\n")
+		for _, diag := range file.Diagnostics {
+			output.WriteString(formatDiagnosticLine(diag) + "\n")
+			if diag.Snippet != "" {
+				output.WriteString(fmt.Sprintf("    %s\n", diag.Snippet))
+			}
+		}
+	}
+
+	return output.String()
+}
+
+// GetWorkspaceDiagnostics reports the diagnostics of the whole workspace,
+// grouped by file with per-file counts. Diagnostics are pulled with
+// workspace/diagnostic from servers that support it; for other servers the
+// diagnostics they have published so far are used.
+func GetWorkspaceDiagnostics(ctx context.Context, clients []*lsp.Client, workspaceDir string, filter DiagnosticsFilter, summaryOnly bool) (*WorkspaceDiagnosticsResult, error) {
+	minSeverity, err := parseSeverity(filter.MinSeverity)
+	if err != nil {
+		return nil, err
+	}
+
+	byURI := make(map[protocol.DocumentUri][]protocol.Diagnostic)
+	result := &WorkspaceDiagnosticsResult{
+		Sources:      []string{},
+		Counts:       make(map[string]int),
+		Files:        []FileDiagnostics{},
+		workspaceDir: workspaceDir,
+		summaryOnly:  summaryOnly,
+	}
+	for _, client := range clients {
+		diagnostics, source := collectWorkspaceDiagnostics(ctx, client)
+		result.Sources = append(result.Sources, source)
+		for uri, diags := range diagnostics {
+			filePath := strings.TrimPrefix(string(uri), "file://")
+			if filter.PathGlob != "" && !matchesPathGlob(filter.PathGlob, filePath, workspaceDir) {
+				continue
+			}
+			for _, diag := range diags {
+				if !matchesDiagnosticFilter(diag, minSeverity, filter) {
+					continue
+				}
+				byURI[uri] = append(byURI[uri], diag)
+			}
+		}
+	}
+
+	for uri, diags := range byURI {
+		sort.SliceStable(diags, func(i, j int) bool {
+			a, b := diags[i].Range.Start, diags[j].Range.Start
+			if a.Line != b.Line {
+				return a.Line < b.Line
+			}
+			return a.Character < b.Character
+		})
+
+		file := FileDiagnostics{
+			Path:        strings.TrimPrefix(string(uri), "file://"),
+			Diagnostics: newDiagnostics(uri, diags),
+		}
+		file.Counts = severityCounts(file.Diagnostics)
+		for severity, c := range file.Counts {
+			result.Counts[severity] += c
+		}
+
+		if summaryOnly {
+			file.Diagnostics = nil
+		} else if content, err := os.ReadFile(file.Path); err == nil {
+			lines := strings.Split(string(content), "\n")
+			for i, diag := range diags {
+				if int(diag.Range.Start.Line) < len(lines) {
+					file.Diagnostics[i].Snippet = strings.TrimSpace(lines[diag.Range.Start.Line])
+				}
+			}
+		}
+		result.Files = append(result.Files, file)
+	}
+	sort.Slice(result.Files, func(i, j int) bool {
+		return result.Files[i].Path < result.Files[j].Path
+	})
+
+	return result, nil
+}
+
+// collectWorkspaceDiagnostics returns the diagnostics of a client's workspace and
+// where they came from. Servers that support workspace diagnostics are asked
+// for them, others fall back to the publishDiagnostics cache.
+func collectWorkspaceDiagnostics(ctx context.Context, client *lsp.Client) (map[protocol.DocumentUri][]protocol.Diagnostic, string) {
+	if supportsWorkspaceDiagnostics(client) {
+		report, err := client.DiagnosticWorkspace(ctx, protocol.WorkspaceDiagnosticParams{
+			PreviousResultIds: []protocol.PreviousResultId{},
+		})
+		if err == nil {
+			diagnostics := make(map[protocol.DocumentUri][]protocol.Diagnostic)
+			for _, item := range report.Items {
+				// Unchanged reports only occur for previous result IDs, which are never sent
+				if full, ok := item.Value.(protocol.WorkspaceFullDocumentDiagnosticReport); ok {
+					diagnostics[full.URI] = append(diagnostics[full.URI], full.Items...)
+				}
+			}
+			return diagnostics, "workspace/diagnostic"
+		}
+		log.Printf("failed to pull workspace diagnostics, using published diagnostics: %v", err)
+	}
+	return client.GetAllDiagnostics(), "published diagnostics"
+}
+
+// supportsWorkspaceDiagnostics reports whether the server supports the
+// workspace/diagnostic request.
+func supportsWorkspaceDiagnostics(client *lsp.Client) bool {
+	provider := client.ServerCapabilities().DiagnosticProvider
+	if provider == nil {
+		return false
+	}
+	switch options := provider.Value.(type) {
+	case protocol.DiagnosticOptions:
+		return options.WorkspaceDiagnostics
+	case protocol.DiagnosticRegistrationOptions:
+		return options.WorkspaceDiagnostics
+	}
+	return false
+}
+
+// parseSeverity converts a severity name to its protocol value. An empty name
+// includes every severity.
+func parseSeverity(name string) (protocol.DiagnosticSeverity, error) {
+	switch strings.ToLower(name) {
+	case "", "hint":
+		return protocol.SeverityHint, nil
+	case "error":
+		return protocol.SeverityError, nil
+	case "warning":
+		return protocol.SeverityWarning, nil
+	case "info", "information":
+		return protocol.SeverityInformation, nil
+	}
+	return 0, fmt.Errorf("invalid severity: %s. Must be 'error', 'warning', 'info' or 'hint'", name)
+}
+
+// matchesDiagnosticFilter reports whether a diagnostic is at least as severe as
+// minSeverity and matches the source and code of the filter. Diagnostics
+// without a severity are always included.
+func matchesDiagnosticFilter(diag protocol.Diagnostic, minSeverity protocol.DiagnosticSeverity, filter DiagnosticsFilter) bool {
+	if diag.Severity != 0 && diag.Severity > minSeverity {
+		return false
+	}
+	if filter.Source != "" && !strings.EqualFold(diag.Source, filter.Source) {
+		return false
+	}
+	if filter.Code != "" && (diag.Code == nil || fmt.Sprint(diag.Code) != filter.Code) {
+		return false
+	}
+	return true
+}
+
+// matchesPathGlob reports whether the workspace-relative or absolute form of
+// filePath matches pattern.
+func matchesPathGlob(pattern, filePath, workspaceDir string) bool {
+	pattern = filepath.ToSlash(pattern)
+	if utilities.MatchGlob(pattern, filepath.ToSlash(filePath)) {
+		return true
+	}
+	rel := relativePath(filePath, workspaceDir)
+	return rel != filePath && utilities.MatchGlob(pattern, filepath.ToSlash(rel))
+}
+
+// relativePath returns filePath relative to workspaceDir, or filePath itself if
+// it lies outside of it.
+func relativePath(filePath, workspaceDir string) string {
+	if workspaceDir == "" {
+		return filePath
+	}
+	rel, err := filepath.Rel(workspaceDir, filePath)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return filePath
+	}
+	return rel
+}
+
+// severityCounts counts diagnostics by severity.
+func severityCounts(diags []Diagnostic) map[string]int {
+	counts := make(map[string]int)
+	for _, diag := range diags {
+		counts[diag.Severity]++
+	}
+	return counts
+}
+
+// formatSeverityCounts formats counts such as "2 errors, 1 warning".
+func formatSeverityCounts(counts map[string]int) string {
+	names := []struct {
+		severity string
+		plural   string
+	}{
+		{"error", "errors"},
+		{"warning", "warnings"},
+		{"info", "info"},
+		{"hint", "hints"},
+		{"unknown", "unknown"},
+	}
+
+	var parts []string
+	for _, n := range names {
+		switch c := counts[n.severity]; {
+		case c == 1:
+			parts = append(parts, "1 "+n.severity)
+		case c > 1:
+			parts = append(parts, fmt.Sprintf("%d %s", c, n.plural))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,54 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchesPathGlob(t *testing.T) {
+	workspace := "/work/project"
+
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{"**/*.go", "/work/project/main.go", true},
+		{"**/*.go", "/work/project/internal/lsp/client.go", true},
+		{"internal/**", "/work/project/internal/lsp/client.go", true},
+		{"internal/**/*_test.go", "/work/project/internal/lsp/client.go", false},
+		{"internal/*.go", "/work/project/internal/lsp/client.go", false},
+		{"*.go", "/work/project/main.go", true},
+		{"/work/project/**/client.go", "/work/project/internal/lsp/client.go", true},
+		{"**/*.ts", "/elsewhere/index.ts", true},
+		{"src/**", "/elsewhere/src/index.ts", false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, matchesPathGlob(tt.pattern, tt.path, workspace), "%s against %s", tt.pattern, tt.path)
+	}
+}
+
+func TestMatchesDiagnosticFilter(t *testing.T) {
+	diag := protocol.Diagnostic{
+		Severity: protocol.SeverityWarning,
+		Source:   "compiler",
+		Code:     float64(2304),
+	}
+
+	assert.True(t, matchesDiagnosticFilter(diag, protocol.SeverityHint, DiagnosticsFilter{}))
+	assert.True(t, matchesDiagnosticFilter(diag, protocol.SeverityWarning, DiagnosticsFilter{Source: "Compiler", Code: "2304"}))
+	assert.False(t, matchesDiagnosticFilter(diag, protocol.SeverityError, DiagnosticsFilter{}))
+	assert.False(t, matchesDiagnosticFilter(diag, protocol.SeverityHint, DiagnosticsFilter{Source: "lint"}))
+	assert.False(t, matchesDiagnosticFilter(diag, protocol.SeverityHint, DiagnosticsFilter{Code: "2305"}))
+}
+
+func TestFormatSeverityCounts(t *testing.T) {
+	counts := map[string]int{
+		"error":   2,
+		"warning": 1,
+	}
+	assert.Equal(t, "2 errors, 1 warning", formatSeverityCounts(counts))
+}
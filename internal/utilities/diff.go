@@ -0,0 +1,231 @@
+package utilities
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContextLines is the number of unchanged lines shown around each change.
+const diffContextLines = 3
+
+type diffOpKind byte
+
+const (
+	diffEqual  diffOpKind = ' '
+	diffDelete diffOpKind = '-'
+	diffInsert diffOpKind = '+'
+)
+
+type diffOp struct {
+	kind diffOpKind
+	text string
+}
+
+// UnifiedDiff returns a unified diff between the before and after contents of
+// the file at path, or an empty string if they are identical.
+func UnifiedDiff(path string, before, after string) string {
+	if before == after {
+		return ""
+	}
+
+	ops := diffLines(splitDiffLines(before), splitDiffLines(after))
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("--- a/%s\n", strings.TrimPrefix(path, "/")))
+	result.WriteString(fmt.Sprintf("+++ b/%s\n", strings.TrimPrefix(path, "/")))
+
+	// Line numbers (0-based) in before and after at the start of each op
+	oldLines := make([]int, len(ops)+1)
+	newLines := make([]int, len(ops)+1)
+	for i, op := range ops {
+		oldLines[i+1] = oldLines[i]
+		newLines[i+1] = newLines[i]
+		if op.kind != diffInsert {
+			oldLines[i+1]++
+		}
+		if op.kind != diffDelete {
+			newLines[i+1]++
+		}
+	}
+
+	for i := 0; i < len(ops); {
+		if ops[i].kind == diffEqual {
+			i++
+			continue
+		}
+
+		// Extend the hunk while the next change is close enough to share context
+		start := max(i-diffContextLines, 0)
+		end := i
+		for end < len(ops) {
+			if ops[end].kind != diffEqual {
+				end++
+				continue
+			}
+			next := end
+			for next < len(ops) && ops[next].kind == diffEqual {
+				next++
+			}
+			if next == len(ops) || next-end > 2*diffContextLines {
+				end = min(end+diffContextLines, len(ops))
+				break
+			}
+			end = next
+		}
+
+		oldCount := oldLines[end] - oldLines[start]
+		newCount := newLines[end] - newLines[start]
+		result.WriteString(fmt.Sprintf("@@ -%s +%s @@\n",
+			hunkRange(oldLines[start], oldCount),
+			hunkRange(newLines[start], newCount)))
+		for _, op := range ops[start:end] {
+			result.WriteString(fmt.Sprintf("%c%s\n", op.kind, op.text))
+		}
+
+		i = end
+	}
+
+	return result.String()
+}
+
+// hunkRange formats the start,count part of a hunk header. Empty ranges refer to
+// the line before the change, as in diff -u.
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+// splitDiffLines splits content into lines, ignoring the final line ending.
+func splitDiffLines(content string) []string {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	if content == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+}
+
+// diffMaxEditDistance bounds the edit distance diffLines searches for. The
+// trace it keeps to recover the edit script grows quadratically with the
+// distance, so larger rewrites are reported as replacing the changed region.
+const diffMaxEditDistance = 1000
+
+// diffLines computes the shortest edit script between a and b using Myers'
+// O(ND) algorithm.
+func diffLines(a, b []string) []diffOp {
+	// Lines shared at the start and end are not part of the search
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix &&
+		a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var ops []diffOp
+	for _, line := range a[:prefix] {
+		ops = append(ops, diffOp{kind: diffEqual, text: line})
+	}
+	ops = append(ops, diffMiddle(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, line := range a[len(a)-suffix:] {
+		ops = append(ops, diffOp{kind: diffEqual, text: line})
+	}
+	return ops
+}
+
+// diffMiddle runs the Myers search between a and b. Only the diagonals reached
+// at each step are kept in the trace, so it uses O(D^2) memory for an edit
+// distance D, and falls back to deleting all of a and inserting all of b when D
+// exceeds diffMaxEditDistance.
+func diffMiddle(a, b []string) []diffOp {
+	n, m := len(a), len(b)
+	maxD := min(n+m, diffMaxEditDistance)
+	offset := maxD + 1
+	v := make([]int, 2*maxD+3)
+	// trace[d] holds v for diagonals -(d-1)..d-1 before step d
+	var trace [][]int
+	found := false
+
+search:
+	for d := 0; d <= maxD; d++ {
+		if d > 0 {
+			trace = append(trace, append([]int(nil), v[offset-d+1:offset+d]...))
+		} else {
+			trace = append(trace, nil)
+		}
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				found = true
+				break search
+			}
+		}
+	}
+
+	if !found {
+		ops := make([]diffOp, 0, n+m)
+		for _, line := range a {
+			ops = append(ops, diffOp{kind: diffDelete, text: line})
+		}
+		for _, line := range b {
+			ops = append(ops, diffOp{kind: diffInsert, text: line})
+		}
+		return ops
+	}
+
+	// Walk the trace backwards to recover the edit script
+	var ops []diffOp
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		window := trace[d]
+		at := func(k int) int {
+			return window[k+d-1]
+		}
+		k := x - y
+		prevX, prevY := 0, 0
+		if d > 0 {
+			prevK := k - 1
+			if k == -d || (k != d && at(k-1) < at(k+1)) {
+				prevK = k + 1
+			}
+			prevX = at(prevK)
+			prevY = prevX - prevK
+		}
+
+		for x > prevX && y > prevY {
+			ops = append(ops, diffOp{kind: diffEqual, text: a[x-1]})
+			x--
+			y--
+		}
+		if d > 0 {
+			if x == prevX {
+				ops = append(ops, diffOp{kind: diffInsert, text: b[y-1]})
+			} else {
+				ops = append(ops, diffOp{kind: diffDelete, text: a[x-1]})
+			}
+		}
+		x, y = prevX, prevY
+	}
+
+	for i, j := 0, len(ops)-1; i < j; i, j = i+1, j-1 {
+		ops[i], ops[j] = ops[j], ops[i]
+	}
+	return ops
+}
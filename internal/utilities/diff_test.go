@@ -0,0 +1,91 @@
+package utilities
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnifiedDiff_Identical(t *testing.T) {
+	assert.Equal(t, "", UnifiedDiff("/tmp/a.go", "a\nb\n", "a\nb\n"))
+}
+
+func TestUnifiedDiff_SingleChange(t *testing.T) {
+	before := "1\n2\n3\n4\n5\n6\n7\n8\n9\n"
+	after := "1\n2\n3\n4\nfive\n6\n7\n8\n9\n"
+
+	expected := strings.Join([]string{
+		"--- a/tmp/a.go",
+		"+++ b/tmp/a.go",
+		"@@ -2,7 +2,7 @@",
+		" 2",
+		" 3",
+		" 4",
+		"-5",
+		"+five",
+		" 6",
+		" 7",
+		" 8",
+		"",
+	}, "\n")
+	assert.Equal(t, expected, UnifiedDiff("/tmp/a.go", before, after))
+}
+
+func TestUnifiedDiff_SeparateHunks(t *testing.T) {
+	var lines []string
+	for i := 1; i <= 20; i++ {
+		lines = append(lines, strings.Repeat("x", i))
+	}
+	before := strings.Join(lines, "\n") + "\n"
+
+	changed := append([]string(nil), lines...)
+	changed[1] = "first change"
+	changed = append(changed[:15], changed[16:]...) // delete line 16
+	after := strings.Join(changed, "\n") + "\n"
+
+	diff := UnifiedDiff("a.txt", before, after)
+	assert.Equal(t, 2, strings.Count(diff, "@@ -"), "changes far apart should produce two hunks")
+	assert.Contains(t, diff, "@@ -1,5 +1,5 @@\n x\n-xx\n+first change\n")
+	assert.Contains(t, diff, "@@ -13,7 +13,6 @@\n")
+	assert.Contains(t, diff, "\n-"+strings.Repeat("x", 16)+"\n")
+}
+
+func TestUnifiedDiff_InsertIntoEmptyFile(t *testing.T) {
+	expected := "--- a/new.txt\n+++ b/new.txt\n@@ -0,0 +1,2 @@\n+hello\n+world\n"
+	assert.Equal(t, expected, UnifiedDiff("new.txt", "", "hello\nworld\n"))
+}
+
+func TestUnifiedDiff_LargeRewrite(t *testing.T) {
+	var before, after strings.Builder
+	for i := 0; i < 5000; i++ {
+		before.WriteString(fmt.Sprintf("old line %d\n", i))
+		after.WriteString(fmt.Sprintf("new line %d\n", i))
+	}
+
+	diff := UnifiedDiff("big.txt", before.String(), after.String())
+	assert.Equal(t, 1, strings.Count(diff, "@@ -"))
+	assert.Contains(t, diff, "@@ -1,5000 +1,5000 @@\n-old line 0\n")
+	assert.Contains(t, diff, "\n-old line 4999\n+new line 0\n")
+	assert.True(t, strings.HasSuffix(diff, "\n+new line 4999\n"))
+}
+
+func TestUnifiedDiff_LargeFileSmallChanges(t *testing.T) {
+	var lines []string
+	for i := 0; i < 5000; i++ {
+		lines = append(lines, fmt.Sprintf("line %d", i))
+	}
+	before := strings.Join(lines, "\n") + "\n"
+
+	changed := append([]string(nil), lines...)
+	for i := 100; i < 5000; i += 1000 {
+		changed[i] = "changed"
+	}
+	after := strings.Join(changed, "\n") + "\n"
+
+	diff := UnifiedDiff("big.txt", before, after)
+	assert.Equal(t, 5, strings.Count(diff, "@@ -"))
+	assert.Equal(t, 5, strings.Count(diff, "\n+changed\n"))
+	assert.Contains(t, diff, "@@ -98,7 +98,7 @@\n line 97\n line 98\n line 99\n-line 100\n+changed\n")
+}
@@ -19,6 +19,21 @@ func applyTextEdits(uri protocol.DocumentUri, edits []protocol.TextEdit) error {
 		return fmt.Errorf("failed to read file: %w", err)
 	}
 
+	newContent, err := applyTextEditsToContent(content, edits)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(path, newContent, 0644); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	return nil
+}
+
+// applyTextEditsToContent applies edits to content in memory, preserving its
+// line endings.
+func applyTextEditsToContent(content []byte, edits []protocol.TextEdit) ([]byte, error) {
 	// Detect line ending style
 	var lineEnding string
 	if bytes.Contains(content, []byte("\r\n")) {
@@ -37,7 +52,7 @@ func applyTextEdits(uri protocol.DocumentUri, edits []protocol.TextEdit) error {
 	for i := 0; i < len(edits); i++ {
 		for j := i + 1; j < len(edits); j++ {
 			if rangesOverlap(edits[i].Range, edits[j].Range) {
-				return fmt.Errorf("overlapping edits detected between edit %d and %d", i, j)
+				return nil, fmt.Errorf("overlapping edits detected between edit %d and %d", i, j)
 			}
 		}
 	}
@@ -56,7 +71,7 @@ func applyTextEdits(uri protocol.DocumentUri, edits []protocol.TextEdit) error {
 	for _, edit := range sortedEdits {
 		newLines, err := applyTextEdit(lines, edit, lineEnding)
 		if err != nil {
-			return fmt.Errorf("failed to apply edit: %w", err)
+			return nil, fmt.Errorf("failed to apply edit: %w", err)
 		}
 		lines = newLines
 	}
@@ -75,11 +90,7 @@ func applyTextEdits(uri protocol.DocumentUri, edits []protocol.TextEdit) error {
 		newContent.WriteString(lineEnding)
 	}
 
-	if err := os.WriteFile(path, []byte(newContent.String()), 0644); err != nil {
-		return fmt.Errorf("failed to write file: %w", err)
-	}
-
-	return nil
+	return []byte(newContent.String()), nil
 }
 
 func applyTextEdit(lines []string, edit protocol.TextEdit, lineEnding string) ([]string, error) {
@@ -225,6 +236,168 @@ func ApplyWorkspaceEdit(edit protocol.WorkspaceEdit) error {
 	return nil
 }
 
+// PreviewWorkspaceEdit applies the given WorkspaceEdit to in-memory copies of
+// the affected files and returns a unified diff for each changed file, keyed by
+// path. Nothing is written to disk.
+func PreviewWorkspaceEdit(edit protocol.WorkspaceEdit) (map[string]string, error) {
+	p := &editPreview{
+		original: make(map[string]*string),
+		current:  make(map[string]*string),
+		renames:  make(map[string]string),
+	}
+
+	for uri, textEdits := range edit.Changes {
+		if err := p.applyTextEdits(uri, textEdits); err != nil {
+			return nil, fmt.Errorf("failed to apply text edits: %w", err)
+		}
+	}
+
+	for _, change := range edit.DocumentChanges {
+		if err := p.applyDocumentChange(change); err != nil {
+			return nil, fmt.Errorf("failed to apply document change: %w", err)
+		}
+	}
+
+	return p.diffs(), nil
+}
+
+// editPreview tracks file contents while previewing a WorkspaceEdit. A nil
+// content means the file does not exist.
+type editPreview struct {
+	original map[string]*string
+	current  map[string]*string
+	// renames maps the new path of a renamed file to its old path
+	renames map[string]string
+}
+
+// load returns the current content of path, reading it from disk the first
+// time it is used.
+func (p *editPreview) load(path string) *string {
+	if content, ok := p.current[path]; ok {
+		return content
+	}
+	var content *string
+	if data, err := os.ReadFile(path); err == nil {
+		text := string(data)
+		content = &text
+	}
+	p.original[path] = content
+	p.current[path] = content
+	return content
+}
+
+func (p *editPreview) applyTextEdits(uri protocol.DocumentUri, edits []protocol.TextEdit) error {
+	path := strings.TrimPrefix(string(uri), "file://")
+	content := p.load(path)
+	if content == nil {
+		return fmt.Errorf("failed to read file: %s does not exist", path)
+	}
+	newContent, err := applyTextEditsToContent([]byte(*content), edits)
+	if err != nil {
+		return err
+	}
+	text := string(newContent)
+	p.current[path] = &text
+	return nil
+}
+
+func (p *editPreview) applyDocumentChange(change protocol.DocumentChange) error {
+	if change.CreateFile != nil {
+		path := strings.TrimPrefix(string(change.CreateFile.URI), "file://")
+		if p.load(path) != nil && change.CreateFile.Options != nil && change.CreateFile.Options.IgnoreIfExists && !change.CreateFile.Options.Overwrite {
+			return nil
+		}
+		empty := ""
+		p.current[path] = &empty
+	}
+
+	if change.DeleteFile != nil {
+		path := strings.TrimPrefix(string(change.DeleteFile.URI), "file://")
+		if p.load(path) == nil {
+			return fmt.Errorf("failed to delete file: %s does not exist", path)
+		}
+		p.current[path] = nil
+	}
+
+	if change.RenameFile != nil {
+		oldPath := strings.TrimPrefix(string(change.RenameFile.OldURI), "file://")
+		newPath := strings.TrimPrefix(string(change.RenameFile.NewURI), "file://")
+		content := p.load(oldPath)
+		if content == nil {
+			return fmt.Errorf("failed to rename file: %s does not exist", oldPath)
+		}
+		if p.load(newPath) != nil && (change.RenameFile.Options == nil || !change.RenameFile.Options.Overwrite) {
+			return fmt.Errorf("target file already exists and overwrite is not allowed: %s", newPath)
+		}
+		p.current[newPath] = content
+		p.current[oldPath] = nil
+		if from, ok := p.renames[oldPath]; ok {
+			oldPath = from
+		}
+		p.renames[newPath] = oldPath
+	}
+
+	if change.TextDocumentEdit != nil {
+		textEdits := make([]protocol.TextEdit, len(change.TextDocumentEdit.Edits))
+		for i, edit := range change.TextDocumentEdit.Edits {
+			var err error
+			textEdits[i], err = edit.AsTextEdit()
+			if err != nil {
+				return fmt.Errorf("invalid edit type: %w", err)
+			}
+		}
+		return p.applyTextEdits(change.TextDocumentEdit.TextDocument.URI, textEdits)
+	}
+
+	return nil
+}
+
+// diffs returns the unified diff of every file that changed. Renamed files are
+// diffed against their old content under a git-style rename header, and their
+// old paths are left out.
+func (p *editPreview) diffs() map[string]string {
+	renamedFrom := make(map[string]bool)
+	for newPath, oldPath := range p.renames {
+		if p.current[newPath] != nil {
+			renamedFrom[oldPath] = true
+		}
+	}
+
+	diffs := make(map[string]string)
+	for path, content := range p.current {
+		if renamedFrom[path] && content == nil {
+			continue
+		}
+
+		before := p.original[path]
+		header := ""
+		if oldPath, ok := p.renames[path]; ok && content != nil {
+			before = p.original[oldPath]
+			header = fmt.Sprintf("rename from %s\nrename to %s\n", oldPath, path)
+		}
+
+		diff := UnifiedDiff(path, derefContent(before), derefContent(content))
+		switch {
+		case header != "":
+			diffs[path] = header + diff
+		case diff != "":
+			diffs[path] = diff
+		case before == nil && content != nil:
+			diffs[path] = fmt.Sprintf("new empty file %s\n", path)
+		case before != nil && content == nil:
+			diffs[path] = fmt.Sprintf("deleted empty file %s\n", path)
+		}
+	}
+	return diffs
+}
+
+func derefContent(content *string) string {
+	if content == nil {
+		return ""
+	}
+	return *content
+}
+
 func rangesOverlap(r1, r2 protocol.Range) bool {
 	if r1.Start.Line > r2.End.Line || r2.Start.Line > r1.End.Line {
 		return false
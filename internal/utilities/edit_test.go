@@ -0,0 +1,74 @@
+package utilities
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreviewWorkspaceEdit_DoesNotWrite(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "main.go")
+	require.NoError(t, os.WriteFile(path, []byte("package main\n\nfunc foo() {}\n"), 0644))
+
+	uri := protocol.DocumentUri("file://" + path)
+	edit := protocol.WorkspaceEdit{
+		Changes: map[protocol.DocumentUri][]protocol.TextEdit{
+			uri: {{
+				Range: protocol.Range{
+					Start: protocol.Position{Line: 2, Character: 5},
+					End:   protocol.Position{Line: 2, Character: 8},
+				},
+				NewText: "bar",
+			}},
+		},
+	}
+
+	diffs, err := PreviewWorkspaceEdit(edit)
+	require.NoError(t, err)
+	assert.Equal(t, UnifiedDiff(path, "package main\n\nfunc foo() {}\n", "package main\n\nfunc bar() {}\n"), diffs[path])
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "package main\n\nfunc foo() {}\n", string(content))
+}
+
+func TestPreviewWorkspaceEdit_RenameFile(t *testing.T) {
+	dir := t.TempDir()
+	oldPath := filepath.Join(dir, "old.ts")
+	newPath := filepath.Join(dir, "new.ts")
+	require.NoError(t, os.WriteFile(oldPath, []byte("export class Old {}\n"), 0644))
+
+	edit := protocol.WorkspaceEdit{
+		DocumentChanges: []protocol.DocumentChange{
+			{RenameFile: &protocol.RenameFile{
+				Kind:   "rename",
+				OldURI: protocol.DocumentUri("file://" + oldPath),
+				NewURI: protocol.DocumentUri("file://" + newPath),
+			}},
+		},
+	}
+
+	diffs, err := PreviewWorkspaceEdit(edit)
+	require.NoError(t, err)
+	assert.Len(t, diffs, 1)
+	assert.Equal(t, "rename from "+oldPath+"\nrename to "+newPath+"\n", diffs[newPath])
+
+	_, err = os.Stat(oldPath)
+	assert.NoError(t, err)
+}
+
+func TestPreviewWorkspaceEdit_MissingFile(t *testing.T) {
+	edit := protocol.WorkspaceEdit{
+		Changes: map[protocol.DocumentUri][]protocol.TextEdit{
+			protocol.DocumentUri("file://" + filepath.Join(t.TempDir(), "missing.go")): {{NewText: "x"}},
+		},
+	}
+
+	_, err := PreviewWorkspaceEdit(edit)
+	assert.Error(t, err)
+}
@@ -9,12 +9,15 @@ import (
 	"os"
 	"os/exec" // Re-enable for command validation
 	"os/signal"
+	"path"
 	"path/filepath" // Re-enable for path manipulation
 	"strings"       // Added for extension checking
 	"syscall"
 	"time"
 
 	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	internalTools "github.com/isaacphi/mcp-language-server/internal/tools"
 	"github.com/isaacphi/mcp-language-server/internal/watcher"
 	"github.com/metoro-io/mcp-golang"
 	"github.com/metoro-io/mcp-golang/transport/stdio"
@@ -27,34 +30,49 @@ func init() {
 	// Define command-line flags
 	flag.StringVar(&configPath, "config", "config.json", "Path to the configuration JSON file")
 	// Add other flags here if needed in the future
-	// Need to parse flags early before loading config
-	flag.Parse()
 }
 
 // Note: Old 'config' struct definition removed
 
 type server struct {
-	config              Config                 // Use new Config type
-	lspClients          map[string]*lsp.Client // Map language name to LSP client
-	extensionToLanguage map[string]string      // Map file extension to language name
-	mcpServer           *mcp_golang.Server
-	ctx                 context.Context
-	cancelFunc          context.CancelFunc
-	workspaceWatcher    *watcher.WorkspaceWatcher
+	config           Config                     // Use new Config type
+	languageServers  map[string]*languageServer // Map language name to its language server
+	languageDetector *lsp.LanguageDetector      // Maps files to language names
+	mcpServer        *mcp_golang.Server
+	ctx              context.Context
+	cancelFunc       context.CancelFunc
+	workspaceWatcher *watcher.WorkspaceWatcher
 }
 
 // LanguageServerConfig defines the configuration for a single language server
 type LanguageServerConfig struct {
-	Language   string   `json:"language"`   // e.g., "typescript", "go"
-	Command    string   `json:"command"`    // e.g., "typescript-language-server", "gopls"
-	Args       []string `json:"args"`       // Arguments for the LSP command
-	Extensions []string `json:"extensions"` // File extensions associated with this language, e.g., [".ts", ".tsx"]
+	Language   string            `json:"language"`             // e.g., "typescript", "go"
+	Command    string            `json:"command"`              // e.g., "typescript-language-server", "gopls"
+	Args       []string          `json:"args"`                 // Arguments for the LSP command
+	Extensions []string          `json:"extensions"`           // File extensions associated with this language, e.g., [".ts", ".tsx"]
+	Filenames  []string          `json:"filenames,omitempty"`  // File names associated with this language, e.g., ["Dockerfile"]
+	Patterns   []string          `json:"patterns,omitempty"`   // Globs of files of this language, e.g., ["*.h", "include/**/*.inc"]
+	Shebangs   []string          `json:"shebangs,omitempty"`   // Interpreters in shebang lines, e.g., ["python3"]
+	Modelines  []string          `json:"modelines,omitempty"`  // Names in vim/emacs modelines besides the language and languageId
+	LanguageID string            `json:"languageId,omitempty"` // Language ID sent when opening files, e.g., "cpp"; detected from the file name if empty
+	Formatting *FormattingConfig `json:"formatting,omitempty"` // Options sent with formatting requests
+	// How long to wait for servers without pull diagnostics to publish diagnostics, in milliseconds
+	DiagnosticsTimeoutMs int `json:"diagnosticsTimeoutMs,omitempty"`
+	// When to start the server: "eager" (default) at boot or "lazy" on the first tool call for its language
+	Startup string `json:"startup,omitempty"`
+}
+
+// FormattingConfig defines the formatting options sent to a language server
+type FormattingConfig struct {
+	TabSize      *uint32 `json:"tabSize,omitempty"`      // Size of a tab in spaces
+	InsertSpaces *bool   `json:"insertSpaces,omitempty"` // Prefer spaces over tabs
 }
 
 // Config holds the overall configuration for the mcp-language-server
 type Config struct {
 	WorkspaceDir  string                 `json:"workspaceDir"`
 	LanguageServers []LanguageServerConfig `json:"languageServers"`
+	OutputFormat  string                 `json:"outputFormat,omitempty"` // Default output format of tools, "text" or "json"
 }
 
 /* // Comment out the old parseConfig function
@@ -124,6 +142,9 @@ func loadConfig(configPath string) (*Config, error) {
 	if _, err := os.Stat(config.WorkspaceDir); os.IsNotExist(err) {
 		return nil, fmt.Errorf("config error: workspaceDir '%s' does not exist", config.WorkspaceDir)
 	}
+	if err := internalTools.ValidateFormat(config.OutputFormat); err != nil {
+		return nil, fmt.Errorf("config error: outputFormat: %w", err)
+	}
 
 
 	if len(config.LanguageServers) == 0 {
@@ -150,8 +171,26 @@ func loadConfig(configPath string) (*Config, error) {
 			// but we might allow it here and let the execution fail later. Or add an explicit check?
 		}
 
-		if len(lsConfig.Extensions) == 0 {
-			log.Printf("Warning: No file extensions specified for language '%s'", lsConfig.Language)
+		if lsConfig.DiagnosticsTimeoutMs < 0 {
+			return nil, fmt.Errorf("config error: diagnosticsTimeoutMs for language '%s' must not be negative", lsConfig.Language)
+		}
+
+		switch lsConfig.Startup {
+		case "":
+			lsConfig.Startup = startupEager
+		case startupEager, startupLazy:
+		default:
+			return nil, fmt.Errorf("config error: invalid startup '%s' for language '%s'. Must be '%s' or '%s'", lsConfig.Startup, lsConfig.Language, startupEager, startupLazy)
+		}
+
+		if len(lsConfig.Extensions) == 0 && len(lsConfig.Filenames) == 0 && len(lsConfig.Patterns) == 0 &&
+			len(lsConfig.Shebangs) == 0 && len(lsConfig.Modelines) == 0 {
+			log.Printf("Warning: No file extensions, names, patterns, shebangs or modelines specified for language '%s'", lsConfig.Language)
+		}
+		for _, pattern := range lsConfig.Patterns {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("config error: invalid pattern '%s' for language '%s': %w", pattern, lsConfig.Language, err)
+			}
 		}
 		// Ensure extensions start with '.'?
 		for j, ext := range lsConfig.Extensions {
@@ -171,21 +210,29 @@ func loadConfig(configPath string) (*Config, error) {
 func newServer(config *Config) (*server, error) { // Use new Config type
 	ctx, cancel := context.WithCancel(context.Background())
 	s := &server{
-		config:              *config, // Assign the new Config
-		lspClients:          make(map[string]*lsp.Client),
-		extensionToLanguage: make(map[string]string),
-		ctx:                 ctx,
-		cancelFunc:          cancel,
+		config:          *config, // Assign the new Config
+		languageServers: make(map[string]*languageServer),
+		ctx:             ctx,
+		cancelFunc:      cancel,
 	}
 
-	// Build extension to language map
+	// Build the rules mapping files to languages
+	var rules []lsp.LanguageRule
 	for _, lsConfig := range config.LanguageServers {
-		for _, ext := range lsConfig.Extensions {
-			if _, exists := s.extensionToLanguage[ext]; exists {
-				log.Printf("Warning: Extension %s is associated with multiple languages. Using %s.", ext, lsConfig.Language)
-			}
-			s.extensionToLanguage[ext] = lsConfig.Language
-		}
+		rules = append(rules, lsp.LanguageRule{
+			Language:   lsConfig.Language,
+			LanguageID: protocol.LanguageKind(lsConfig.LanguageID),
+			Extensions: lsConfig.Extensions,
+			Filenames:  lsConfig.Filenames,
+			Patterns:   lsConfig.Patterns,
+			Shebangs:   lsConfig.Shebangs,
+			Modelines:  lsConfig.Modelines,
+		})
+	}
+	s.languageDetector = lsp.NewLanguageDetector(config.WorkspaceDir, rules)
+
+	for _, lsConfig := range config.LanguageServers {
+		s.languageServers[lsConfig.Language] = newLanguageServer(ctx, lsConfig, s.startClient)
 	}
 
 	return s, nil
@@ -208,6 +255,7 @@ func (s *server) initializeLSP() error {
 			log.Printf("Warning: Failed to create temporary LSP client for watcher: %v. File watching might not work.", err)
 			// Continue without watcher if temp client fails? Or return error? For now, continue.
 		} else {
+			tempClientForWatcher.SetLanguageDetector(s.languageDetector)
 			s.workspaceWatcher = watcher.NewWorkspaceWatcher(tempClientForWatcher)
 			// We don't need to fully initialize this temp client, just use it for watcher registration.
 			// Maybe there's a better way? Refactor watcher later if needed.
@@ -217,48 +265,59 @@ func (s *server) initializeLSP() error {
 		}
 	}
 
-	for _, langCfg := range s.config.LanguageServers {
-		log.Printf("Initializing LSP client for %s: %s %v", langCfg.Language, langCfg.Command, langCfg.Args)
-		client, err := lsp.NewClient(langCfg.Command, langCfg.Args...)
-		if err != nil {
-			// Log error but continue trying to initialize other servers
-			log.Printf("Error creating LSP client for %s: %v", langCfg.Language, err)
+	// Eager servers are initialized in parallel, lazy ones on first use
+	var eager []string
+	lazy := 0
+	for _, language := range s.sortedLanguages() {
+		if s.languageServers[language].config.Startup == startupLazy {
+			log.Printf("%s LSP client will be started on first use", language)
+			lazy++
 			continue
 		}
+		eager = append(eager, language)
+	}
+	s.startServers(eager)
+
+	running := len(s.runningClients())
+	if running == 0 && lazy == 0 {
+		return fmt.Errorf("failed to initialize any LSP clients")
+	}
 
-		// Store the client in the map
-		s.lspClients[langCfg.Language] = client
+	log.Printf("Finished initializing %d LSP client(s), %d to be started on first use", running, lazy)
+	return nil
+}
 
-		// Initialize the client (sends 'initialize' request)
-		initResult, err := client.InitializeLSPClient(s.ctx, s.config.WorkspaceDir) // Use s.config.WorkspaceDir
-		if err != nil {
-			log.Printf("Error initializing LSP client for %s: %v", langCfg.Language, err)
-			// Remove the client from the map if initialization fails?
-			delete(s.lspClients, langCfg.Language)
-			client.Close() // Attempt to clean up the failed client process
-			continue
-		}
+// startClient starts the LSP client of a language server and waits for it to be ready
+func (s *server) startClient(langCfg LanguageServerConfig) (*lsp.Client, error) {
+	log.Printf("Initializing LSP client for %s: %s %v", langCfg.Language, langCfg.Command, langCfg.Args)
+	client, err := lsp.NewClient(langCfg.Command, langCfg.Args...)
+	if err != nil {
+		return nil, fmt.Errorf("error creating LSP client: %w", err)
+	}
 
-		if debug {
-			log.Printf("Initialized %s LSP server. Capabilities: %+v\n\n", langCfg.Language, initResult.Capabilities)
-		}
+	client.SetLanguageDetector(s.languageDetector)
+	if langCfg.DiagnosticsTimeoutMs > 0 {
+		client.SetDiagnosticsTimeout(time.Duration(langCfg.DiagnosticsTimeoutMs) * time.Millisecond)
+	}
 
-		// Wait for server ready (optional, might need adjustment)
-		// Doing this sequentially might slow down startup if many servers
-		// Maybe wait in parallel? For now, sequential.
-		if err := client.WaitForServerReady(s.ctx); err != nil {
-			log.Printf("Error waiting for %s LSP server to be ready: %v", langCfg.Language, err)
-			// Consider this non-fatal for now?
-		}
-		log.Printf("%s LSP client ready.", langCfg.Language)
+	// Initialize the client (sends 'initialize' request)
+	initResult, err := client.InitializeLSPClient(s.ctx, s.config.WorkspaceDir) // Use s.config.WorkspaceDir
+	if err != nil {
+		client.Close() // Attempt to clean up the failed client process
+		return nil, fmt.Errorf("error initializing LSP client: %w", err)
 	}
 
-	if len(s.lspClients) == 0 {
-		return fmt.Errorf("failed to initialize any LSP clients")
+	if debug {
+		log.Printf("Initialized %s LSP server. Capabilities: %+v\n\n", langCfg.Language, initResult.Capabilities)
 	}
 
-	log.Printf("Finished initializing %d LSP client(s)", len(s.lspClients))
-	return nil
+	// Wait for server ready (optional, might need adjustment)
+	if err := client.WaitForServerReady(s.ctx); err != nil {
+		log.Printf("Error waiting for %s LSP server to be ready: %v", langCfg.Language, err)
+		// Consider this non-fatal for now?
+	}
+	log.Printf("%s LSP client ready.", langCfg.Language)
+	return client, nil
 }
 
 func (s *server) start() error {
@@ -276,12 +335,15 @@ func (s *server) start() error {
 }
 
 func main() {
+	// Parse flags before loading config
+	flag.Parse()
+
 	done := make(chan struct{})
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 
 	// Load configuration from file (replace parseConfig)
-	// Config path is now determined by the global 'configPath' variable, set by flags in init()
+	// Config path is now determined by the global 'configPath' variable, set by flags parsed above
 	log.Printf("Using configuration file path from flag: %s", configPath) // Add log here
 	config, err := loadConfig(configPath)
 	if err != nil {
@@ -352,10 +414,14 @@ func cleanup(s *server, done chan struct{}) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	// Cleanup all LSP clients
-	if s.lspClients != nil {
-		log.Printf("Cleaning up %d LSP client(s)...", len(s.lspClients))
-		for lang, client := range s.lspClients {
+	// Stop supervising the language servers so that they are not restarted
+	// when they exit below
+	s.cancelFunc()
+
+	// Cleanup all running LSP clients
+	if clients := s.runningClients(); len(clients) > 0 {
+		log.Printf("Cleaning up %d LSP client(s)...", len(clients))
+		for lang, client := range clients {
 			log.Printf("Cleaning up %s LSP client...", lang)
 			if client != nil {
 				log.Printf("Closing open files for %s", lang)
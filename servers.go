@@ -0,0 +1,315 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	internalTools "github.com/isaacphi/mcp-language-server/internal/tools"
+)
+
+// Startup modes of a language server
+const (
+	startupEager = "eager" // Started when the MCP server starts
+	startupLazy  = "lazy"  // Started the first time a tool call routes to its language
+)
+
+// Delays between attempts to restart a language server that exited. The delay
+// doubles after every failed attempt, and is reset once a restarted server has
+// stayed up for restartBackoffMax.
+const (
+	restartBackoffMin = time.Second
+	restartBackoffMax = time.Minute
+)
+
+// languageServer is a configured language server whose client is started
+// either at boot or on first use. Callers that need the client while it is
+// starting wait for the same initialization, and a server that failed to start
+// is started again by the next caller. Once started, the server is supervised
+// and restarted if it exits.
+type languageServer struct {
+	config LanguageServerConfig
+	ctx    context.Context
+	start  func(LanguageServerConfig) (*lsp.Client, error)
+
+	mu      sync.Mutex
+	attempt *startAttempt // Latest attempt to start the server
+	client  *lsp.Client   // nil while restarting
+	err     error         // Why the latest attempt to start failed
+
+	// Supervision state, guarded by mu
+	state           string
+	restarts        int
+	lastExit        error
+	lastExitTime    time.Time
+	lastRestartTime time.Time
+	nextRestartTime time.Time
+}
+
+// startAttempt is one call of start made by getClient. done is closed once it
+// has returned.
+type startAttempt struct {
+	done   chan struct{}
+	client *lsp.Client
+	err    error
+}
+
+func newLanguageServer(ctx context.Context, config LanguageServerConfig, start func(LanguageServerConfig) (*lsp.Client, error)) *languageServer {
+	return &languageServer{
+		config: config,
+		ctx:    ctx,
+		start:  start,
+		state:  internalTools.ServerNotStarted,
+	}
+}
+
+// getClient returns the client of the server, starting it if it has not been
+// started yet or failed to start.
+func (ls *languageServer) getClient() (*lsp.Client, error) {
+	ls.mu.Lock()
+	attempt := ls.attempt
+	if ls.state == internalTools.ServerNotStarted || ls.state == internalTools.ServerFailed {
+		attempt = &startAttempt{done: make(chan struct{})}
+		ls.attempt = attempt
+		ls.state = internalTools.ServerStarting
+		ls.mu.Unlock()
+
+		attempt.client, attempt.err = ls.start(ls.config)
+
+		ls.mu.Lock()
+		ls.client, ls.err = attempt.client, attempt.err
+		if attempt.err != nil {
+			ls.state = internalTools.ServerFailed
+		} else {
+			ls.state = internalTools.ServerRunning
+			go ls.supervise(attempt.client)
+		}
+		close(attempt.done)
+	}
+	ls.mu.Unlock()
+
+	<-attempt.done
+	if attempt.err != nil {
+		return nil, attempt.err
+	}
+
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	if ls.state == internalTools.ServerRestarting {
+		return nil, fmt.Errorf("the server exited (%v) and is being restarted, retry later", ls.lastExit)
+	}
+	return ls.client, nil
+}
+
+// pending reports whether the server has not finished its first start yet.
+func (ls *languageServer) pending() bool {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	return ls.state == internalTools.ServerNotStarted || ls.state == internalTools.ServerStarting
+}
+
+// runningClient returns the client of the server if it is running, without
+// starting it.
+func (ls *languageServer) runningClient() *lsp.Client {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	return ls.client
+}
+
+// supervise waits for the connection to client to be lost, then restarts the
+// server with exponential backoff and reopens the files that were open in it
+// from their current content on disk. It returns when the MCP server shuts
+// down.
+func (ls *languageServer) supervise(client *lsp.Client) {
+	language := ls.config.Language
+	backoff := restartBackoffMin
+	for {
+		startedAt := time.Now()
+		select {
+		case <-ls.ctx.Done():
+			return
+		case <-client.Done():
+		}
+		if ls.ctx.Err() != nil {
+			return // Shutting down
+		}
+
+		exitErr := client.Err()
+		log.Printf("%s LSP server exited: %v", language, exitErr)
+		openFiles := client.OpenFilePaths()
+
+		ls.mu.Lock()
+		ls.client = nil
+		ls.state = internalTools.ServerRestarting
+		ls.lastExit = exitErr
+		ls.lastExitTime = time.Now()
+		ls.mu.Unlock()
+
+		// Reap the old process
+		if err := client.Close(); err != nil {
+			log.Printf("Error closing exited %s LSP client: %v", language, err)
+		}
+
+		if time.Since(startedAt) > restartBackoffMax {
+			backoff = restartBackoffMin
+		}
+		for {
+			ls.mu.Lock()
+			ls.nextRestartTime = time.Now().Add(backoff)
+			ls.mu.Unlock()
+
+			select {
+			case <-ls.ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+
+			log.Printf("Restarting %s LSP server", language)
+			newClient, err := ls.start(ls.config)
+			if err == nil {
+				client = newClient
+				break
+			}
+			backoff = min(2*backoff, restartBackoffMax)
+			log.Printf("Error restarting %s LSP server, retrying in %s: %v", language, backoff, err)
+		}
+
+		reopened := 0
+		for _, path := range openFiles {
+			if err := client.OpenFile(ls.ctx, path); err != nil {
+				log.Printf("Error reopening %s in restarted %s LSP server: %v", path, language, err)
+				continue
+			}
+			reopened++
+		}
+
+		ls.mu.Lock()
+		ls.client = client
+		ls.state = internalTools.ServerRunning
+		ls.restarts++
+		ls.lastRestartTime = time.Now()
+		ls.nextRestartTime = time.Time{}
+		ls.mu.Unlock()
+		log.Printf("%s LSP server restarted, reopened %d of %d file(s)", language, reopened, len(openFiles))
+
+		backoff = min(2*backoff, restartBackoffMax)
+	}
+}
+
+// status reports the state of the server.
+func (ls *languageServer) status() internalTools.ServerStatus {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+
+	status := internalTools.ServerStatus{
+		Language: ls.config.Language,
+		Command:  ls.config.Command,
+		Startup:  ls.config.Startup,
+		State:    ls.state,
+		Restarts: ls.restarts,
+	}
+	if ls.client != nil {
+		if ls.client.Cmd != nil && ls.client.Cmd.Process != nil {
+			status.PID = ls.client.Cmd.Process.Pid
+		}
+		status.OpenFiles = len(ls.client.OpenFilePaths())
+	}
+	if ls.lastExit != nil {
+		status.LastExit = ls.lastExit.Error()
+		status.LastExitTime = timePtr(ls.lastExitTime)
+	}
+	if !ls.lastRestartTime.IsZero() {
+		status.LastRestartTime = timePtr(ls.lastRestartTime)
+	}
+	if ls.state == internalTools.ServerRestarting {
+		status.NextRestartTime = timePtr(ls.nextRestartTime)
+	}
+	if ls.err != nil {
+		status.Error = ls.err.Error()
+	}
+	return status
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
+
+// Helper function to get the LSP client of a language, starting its server if
+// it is lazy and not running yet
+func (s *server) getClientForLanguage(language string) (*lsp.Client, error) {
+	ls, ok := s.languageServers[language]
+	if !ok {
+		return nil, fmt.Errorf("LSP client for language '%s' not found or not initialized", language)
+	}
+	client, err := ls.getClient()
+	if err != nil {
+		return nil, fmt.Errorf("LSP client for language '%s' is unavailable: %v", language, err)
+	}
+	return client, nil
+}
+
+// Helper function to get the clients of all language servers by language for
+// queries across languages. Lazy servers that have not been started yet are
+// started first, and servers that are starting are waited for.
+func (s *server) getAllClients() map[string]*lsp.Client {
+	var pending []string
+	for _, language := range s.sortedLanguages() {
+		if s.languageServers[language].pending() {
+			pending = append(pending, language)
+		}
+	}
+	s.startServers(pending)
+	return s.runningClients()
+}
+
+// Helper function to get the clients of the language servers that have
+// started successfully
+func (s *server) runningClients() map[string]*lsp.Client {
+	clients := make(map[string]*lsp.Client)
+	for language, ls := range s.languageServers {
+		if client := ls.runningClient(); client != nil {
+			clients[language] = client
+		}
+	}
+	return clients
+}
+
+// startServers starts the servers of the given languages in parallel and
+// waits for them to be initialized. Failures are logged.
+func (s *server) startServers(languages []string) {
+	var wg sync.WaitGroup
+	for _, language := range languages {
+		wg.Add(1)
+		go func(language string) {
+			defer wg.Done()
+			if _, err := s.getClientForLanguage(language); err != nil {
+				log.Printf("Error starting %s LSP client: %v", language, err)
+			}
+		}(language)
+	}
+	wg.Wait()
+}
+
+// Helper function to get the status of every configured language server
+func (s *server) getServerStatus() *internalTools.ServerStatusResult {
+	result := &internalTools.ServerStatusResult{Servers: []internalTools.ServerStatus{}}
+	for _, language := range s.sortedLanguages() {
+		result.Servers = append(result.Servers, s.languageServers[language].status())
+	}
+	return result
+}
+
+// sortedLanguages returns the names of all configured languages in
+// alphabetical order.
+func (s *server) sortedLanguages() []string {
+	languages := make([]string, 0, len(s.languageServers))
+	for language := range s.languageServers {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+	return languages
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	internalTools "github.com/isaacphi/mcp-language-server/internal/tools"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingStart returns a start function that counts its calls and returns a
+// client that never disconnects.
+func countingStart(calls *atomic.Int32) func(LanguageServerConfig) (*lsp.Client, error) {
+	return func(LanguageServerConfig) (*lsp.Client, error) {
+		calls.Add(1)
+		time.Sleep(20 * time.Millisecond)
+		return &lsp.Client{}, nil
+	}
+}
+
+func TestGetClientStartsOnce(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var calls atomic.Int32
+	ls := newLanguageServer(ctx, LanguageServerConfig{Language: "go"}, countingStart(&calls))
+
+	clients := make([]*lsp.Client, 10)
+	var wg sync.WaitGroup
+	for i := range clients {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			client, err := ls.getClient()
+			assert.NoError(t, err)
+			clients[i] = client
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), calls.Load())
+	for _, client := range clients {
+		assert.Same(t, clients[0], client)
+	}
+	assert.Equal(t, internalTools.ServerRunning, ls.status().State)
+}
+
+func TestGetAllClientsStartsLazyServers(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var calls atomic.Int32
+	s := &server{languageServers: map[string]*languageServer{
+		"go":     newLanguageServer(ctx, LanguageServerConfig{Language: "go", Startup: startupEager}, countingStart(&calls)),
+		"python": newLanguageServer(ctx, LanguageServerConfig{Language: "python", Startup: startupLazy}, countingStart(&calls)),
+	}}
+	_, err := s.languageServers["go"].getClient()
+	require.NoError(t, err)
+
+	clients := s.getAllClients()
+	assert.Len(t, clients, 2)
+	assert.Contains(t, clients, "python")
+	assert.Equal(t, int32(2), calls.Load())
+
+	// Servers are not started again by later queries
+	assert.Len(t, s.getAllClients(), 2)
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestGetClientRetriesFailedStart(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	calls := 0
+	ls := newLanguageServer(ctx, LanguageServerConfig{Language: "go"}, func(LanguageServerConfig) (*lsp.Client, error) {
+		calls++
+		if calls == 1 {
+			return nil, errors.New("gopls not found")
+		}
+		return &lsp.Client{}, nil
+	})
+
+	_, err := ls.getClient()
+	assert.EqualError(t, err, "gopls not found")
+	status := ls.status()
+	assert.Equal(t, internalTools.ServerFailed, status.State)
+	assert.Equal(t, "gopls not found", status.Error)
+
+	client, err := ls.getClient()
+	require.NoError(t, err)
+	assert.NotNil(t, client)
+	assert.Equal(t, 2, calls)
+	status = ls.status()
+	assert.Equal(t, internalTools.ServerRunning, status.State)
+	assert.Empty(t, status.Error)
+}
+
+func TestSuperviseRestartsExitedServer(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	path := filepath.Join(t.TempDir(), "main.go")
+	require.NoError(t, os.WriteFile(path, []byte("package main\n"), 0644))
+
+	// cat stands in for a language server that accepts every message
+	var starts atomic.Int32
+	ls := newLanguageServer(ctx, LanguageServerConfig{Language: "go"}, func(LanguageServerConfig) (*lsp.Client, error) {
+		starts.Add(1)
+		return lsp.NewClient("cat")
+	})
+
+	client, err := ls.getClient()
+	require.NoError(t, err)
+	require.NoError(t, client.OpenFile(ctx, path))
+
+	require.NoError(t, client.Cmd.Process.Kill())
+	require.Eventually(t, func() bool {
+		return ls.status().State == internalTools.ServerRestarting
+	}, time.Second, time.Millisecond)
+
+	// Tool calls fail during the backoff instead of waiting for the restart
+	_, err = ls.getClient()
+	assert.ErrorContains(t, err, "is being restarted")
+	status := ls.status()
+	assert.Contains(t, status.LastExit, "language server exited")
+	assert.NotNil(t, status.NextRestartTime)
+
+	require.Eventually(t, func() bool {
+		return ls.status().State == internalTools.ServerRunning
+	}, restartBackoffMin+5*time.Second, 10*time.Millisecond)
+
+	restarted, err := ls.getClient()
+	require.NoError(t, err)
+	assert.NotSame(t, client, restarted)
+	assert.True(t, restarted.IsFileOpen(path), "open file was not reopened")
+	assert.Equal(t, int32(2), starts.Load())
+
+	status = ls.status()
+	assert.Equal(t, 1, status.Restarts)
+	assert.Equal(t, 1, status.OpenFiles)
+	assert.NotNil(t, status.LastRestartTime)
+	assert.Nil(t, status.NextRestartTime)
+
+	cancel()
+	assert.NoError(t, restarted.Close())
+}
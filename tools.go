@@ -3,39 +3,123 @@ package main
 import (
 	"encoding/json" // Import encoding/json
 	"fmt"
-	"path/filepath" // For extension checking
+	"sort"
 
 	"github.com/isaacphi/mcp-language-server/internal/lsp"    // For lsp.Client type
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
 	internalTools "github.com/isaacphi/mcp-language-server/internal/tools" // Alias internal/tools to avoid name clash
 	"github.com/metoro-io/mcp-golang"
 )
 
-// Helper function to get the appropriate LSP client based on file extension
-func (s *server) getClientForFile(filePath string) (*lsp.Client, error) {
-	ext := filepath.Ext(filePath)
-	language, ok := s.extensionToLanguage[ext]
+// Helper function to get the configured language name of a file based on its
+// modeline, shebang, name or extension
+func (s *server) getLanguageForFile(filePath string) (string, error) {
+	detection, ok := s.languageDetector.Detect(filePath, nil)
 	if !ok {
-		return nil, fmt.Errorf("language not supported for file extension: %s (file: %s)", ext, filePath)
+		return "", fmt.Errorf("language not supported for file: %s", filePath)
 	}
+	return detection.Language, nil
+}
 
-	client, ok := s.lspClients[language]
-	if !ok {
-		// This should ideally not happen if initialization succeeded for this language
-		return nil, fmt.Errorf("LSP client for language '%s' not found or not initialized", language)
+// Helper function to get the appropriate LSP client based on the file's language
+func (s *server) getClientForFile(filePath string) (*lsp.Client, error) {
+	language, err := s.getLanguageForFile(filePath)
+	if err != nil {
+		return nil, err
 	}
-	return client, nil
+
+	return s.getClientForLanguage(language)
+}
+
+// Helper function to get the LSP client for a file if one is given, falling back
+// to an explicit language otherwise
+func (s *server) getClientForFileOrLanguage(filePath string, language string) (*lsp.Client, error) {
+	if filePath != "" {
+		return s.getClientForFile(filePath)
+	}
+	if language == "" {
+		return nil, fmt.Errorf("either filePath or language is required")
+	}
+	return s.getClientForLanguage(language)
+}
+
+// Helper function to get the LSP clients to look up a symbol with: the client
+// for a file if one is given, the client of an explicit language otherwise,
+// and the clients of all language servers if neither is given
+func (s *server) getClientsForSymbol(filePath string, language string) (map[string]*lsp.Client, error) {
+	if filePath != "" {
+		fileLanguage, err := s.getLanguageForFile(filePath)
+		if err != nil {
+			return nil, err
+		}
+		language = fileLanguage
+	}
+	if language != "" {
+		client, err := s.getClientForLanguage(language)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]*lsp.Client{language: client}, nil
+	}
+	clients := s.getAllClients()
+	if len(clients) == 0 {
+		return nil, fmt.Errorf("no language servers are initialized")
+	}
+	return clients, nil
+}
+
+// Helper function to render a tool result in the requested output format,
+// falling back to the configured default
+func (s *server) renderResult(result internalTools.Result, format string) (*mcp_golang.ToolResponse, error) {
+	if format == "" {
+		format = s.config.OutputFormat
+	}
+	text, err := internalTools.Render(result, format)
+	if err != nil {
+		return nil, err
+	}
+	return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(text)), nil
+}
+
+// Helper function to get the formatting options configured for a language,
+// defaulting to 4 spaces for the options that are not set
+func (s *server) getFormattingOptions(language string) protocol.FormattingOptions {
+	options := protocol.FormattingOptions{
+		TabSize:      4,
+		InsertSpaces: true,
+	}
+	for _, lsConfig := range s.config.LanguageServers {
+		if lsConfig.Language == language && lsConfig.Formatting != nil {
+			if tabSize := lsConfig.Formatting.TabSize; tabSize != nil && *tabSize > 0 {
+				options.TabSize = *tabSize
+			}
+			if insertSpaces := lsConfig.Formatting.InsertSpaces; insertSpaces != nil {
+				options.InsertSpaces = *insertSpaces
+			}
+		}
+	}
+	return options
+}
+
+// Shared by the args of every tool
+type OutputFormatArgs struct {
+	Format string `json:"format,omitempty" jsonschema:"enum=text,enum=json,description=Output format: 'text' for human-readable output or 'json' for a structured result with 1-based locations. Defaults to the configured outputFormat or text."`
 }
 
 type ReadDefinitionArgs struct {
 	SymbolName      string `json:"symbolName" jsonschema:"required,description=The name of the symbol whose definition you want to find (e.g. 'mypackage.MyFunction', 'MyType.MyMethod')"`
 	ShowLineNumbers bool   `json:"showLineNumbers" jsonschema:"required,default=true,description=Include line numbers in the returned source code"`
-	Language        string `json:"language" jsonschema:"required,description=The programming language of the symbol (e.g., 'typescript', 'go')"` // Added language argument
+	Language        string `json:"language,omitempty" jsonschema:"description=The programming language of the symbol (e.g. 'typescript' or 'go'). If neither language nor filePath is given every language server is queried"`
+	FilePath        string `json:"filePath,omitempty" jsonschema:"description=A file in the language of the symbol. Routes the request to the language server for that file"`
+	OutputFormatArgs
 }
 
 type FindReferencesArgs struct {
 	SymbolName      string `json:"symbolName" jsonschema:"required,description=The name of the symbol to search for (e.g. 'mypackage.MyFunction', 'MyType')"`
 	ShowLineNumbers bool   `json:"showLineNumbers" jsonschema:"required,default=true,description=Include line numbers when showing where the symbol is used"`
-	Language        string `json:"language" jsonschema:"required,description=The programming language of the symbol (e.g., 'typescript', 'go')"` // Added language argument
+	Language        string `json:"language,omitempty" jsonschema:"description=The programming language of the symbol (e.g. 'typescript' or 'go'). If neither language nor filePath is given every language server is queried"`
+	FilePath        string `json:"filePath,omitempty" jsonschema:"description=A file in the language of the symbol. Routes the request to the language server for that file"`
+	OutputFormatArgs
 }
 
 // Integrate notes into the Edits description
@@ -85,7 +169,9 @@ type ApplyTextEditArgs struct {
 		},
 		"required": ["type", "startLine", "endLine"]
 	}`
+	ReportDiagnostics bool `json:"reportDiagnostics,omitempty" jsonschema:"default=false,description=If true, waits for the language server to re-check the file after the edit and reports the diagnostics (errors, warnings, ...) the edit introduced and resolved."`
 	// Removed _editsNotes field
+	OutputFormatArgs
 }
 
 
@@ -93,23 +179,183 @@ type GetDiagnosticsArgs struct {
 	FilePath        string `json:"filePath" jsonschema:"required,description=The path to the file to get diagnostics for"`
 	IncludeContext  bool   `json:"includeContext" jsonschema:"default=false,description=Include additional context for each diagnostic. Prefer false."`
 	ShowLineNumbers bool   `json:"showLineNumbers" jsonschema:"required,default=true,description=If true, adds line numbers to the output"`
+	OutputFormatArgs
 }
 
 type GetCodeLensArgs struct {
 	FilePath string `json:"filePath" jsonschema:"required,description=The path to the file to get code lens information for"`
+	OutputFormatArgs
 }
 
 type ExecuteCodeLensArgs struct {
 	FilePath string `json:"filePath" jsonschema:"required,description=The path to the file containing the code lens to execute"`
 	Index    int    `json:"index" jsonschema:"required,description=The index of the code lens to execute (from get_codelens output), 1 indexed"`
+	OutputFormatArgs
 }
 
 // Define args struct for rename_symbol tool
 type RenameSymbolArgs struct {
-	FilePath  string `json:"filePath" jsonschema:"required,description=Path to the file containing the symbol."`
-	Line      int    `json:"line" jsonschema:"required,description=0-based line number of the symbol."`
-	Character int    `json:"character" jsonschema:"required,description=0-based character offset of the symbol."`
-	NewName   string `json:"newName" jsonschema:"required,description=The new name for the symbol."`
+	FilePath   string `json:"filePath,omitempty" jsonschema:"description=Path to the file containing the symbol. Required unless symbolName is set."`
+	Line       int    `json:"line,omitempty" jsonschema:"description=0-based line number of the symbol. Ignored if symbolName is set."`
+	Character  int    `json:"character,omitempty" jsonschema:"description=0-based character offset of the symbol. Ignored if symbolName is set."`
+	SymbolName string `json:"symbolName,omitempty" jsonschema:"description=Name of the symbol to rename, optionally qualified with its container (e.g. 'MyType.MyMethod'). Resolved across the workspace, or within filePath if given."`
+	Language   string `json:"language,omitempty" jsonschema:"description=The programming language of the symbol (e.g., 'typescript', 'go'). Required if filePath is omitted."`
+	NewName    string `json:"newName" jsonschema:"required,description=The new name for the symbol."`
+	DryRun     bool   `json:"dryRun,omitempty" jsonschema:"description=Return a unified diff per file instead of writing the changes to disk."`
+	OutputFormatArgs
+}
+
+type HoverArgs struct {
+	FilePath   string `json:"filePath" jsonschema:"required,description=The path to the file containing the symbol"`
+	Line       int    `json:"line,omitempty" jsonschema:"description=1-based line number of the symbol. Either line or symbolName is required."`
+	Column     int    `json:"column,omitempty" jsonschema:"description=1-based column of the symbol. Defaults to 1."`
+	SymbolName string `json:"symbolName,omitempty" jsonschema:"description=The name of a symbol declared in the file. Used when line is not provided."`
+	OutputFormatArgs
+}
+
+type CallHierarchyArgs struct {
+	FilePath   string `json:"filePath,omitempty" jsonschema:"description=The path to the file containing the symbol. If omitted, symbolName is searched across the workspace."`
+	Line       int    `json:"line,omitempty" jsonschema:"description=1-based line number of the symbol. Requires filePath."`
+	Column     int    `json:"column,omitempty" jsonschema:"description=1-based column of the symbol. Defaults to 1."`
+	SymbolName string `json:"symbolName,omitempty" jsonschema:"description=The name of the function or method. Used when line is not provided."`
+	Language   string `json:"language,omitempty" jsonschema:"description=The programming language of the symbol (e.g., 'typescript', 'go'). Required if filePath is omitted."`
+	Direction  string `json:"direction,omitempty" jsonschema:"enum=incoming,enum=outgoing,enum=both,default=incoming,description=Which calls to follow: 'incoming' (callers), 'outgoing' (callees) or 'both'."`
+	MaxDepth   int    `json:"maxDepth,omitempty" jsonschema:"default=3,description=Maximum depth of the call tree."`
+	OutputFormatArgs
+}
+
+type TypeHierarchyArgs struct {
+	FilePath   string `json:"filePath,omitempty" jsonschema:"description=The path to the file containing the type. If omitted, symbolName is searched across the workspace."`
+	Line       int    `json:"line,omitempty" jsonschema:"description=1-based line number of the type. Requires filePath."`
+	Column     int    `json:"column,omitempty" jsonschema:"description=1-based column of the type. Defaults to 1."`
+	SymbolName string `json:"symbolName,omitempty" jsonschema:"description=The name of the type, class or interface. Used when line is not provided."`
+	Language   string `json:"language,omitempty" jsonschema:"description=The programming language of the type (e.g., 'typescript', 'go'). Required if filePath is omitted."`
+	Direction  string `json:"direction,omitempty" jsonschema:"enum=supertypes,enum=subtypes,enum=both,default=both,description=Which part of the hierarchy to show: 'supertypes', 'subtypes' (e.g. implementations of an interface) or 'both'."`
+	MaxDepth   int    `json:"maxDepth,omitempty" jsonschema:"default=3,description=Maximum depth of the type tree."`
+	OutputFormatArgs
+}
+
+type FindImplementationsArgs struct {
+	FilePath        string `json:"filePath,omitempty" jsonschema:"description=The path to the file containing the interface, method or type. If omitted, symbolName is searched across the workspace."`
+	Line            int    `json:"line,omitempty" jsonschema:"description=1-based line number of the symbol. Requires filePath."`
+	Column          int    `json:"column,omitempty" jsonschema:"description=1-based column of the symbol. Defaults to 1."`
+	SymbolName      string `json:"symbolName,omitempty" jsonschema:"description=The name of the interface, method or type. Used when line is not provided."`
+	Language        string `json:"language,omitempty" jsonschema:"description=The programming language of the symbol (e.g., 'typescript', 'go'). Required if filePath is omitted."`
+	ShowLineNumbers bool   `json:"showLineNumbers" jsonschema:"default=true,description=Include line numbers in the returned source code"`
+	OutputFormatArgs
+}
+
+type ReadTypeDefinitionArgs struct {
+	FilePath        string `json:"filePath,omitempty" jsonschema:"description=The path to the file containing the symbol. If omitted, symbolName is searched across the workspace."`
+	Line            int    `json:"line,omitempty" jsonschema:"description=1-based line number of the symbol. Requires filePath."`
+	Column          int    `json:"column,omitempty" jsonschema:"description=1-based column of the symbol. Defaults to 1."`
+	SymbolName      string `json:"symbolName,omitempty" jsonschema:"description=The name of the variable, field or parameter whose type should be read. Used when line is not provided."`
+	Language        string `json:"language,omitempty" jsonschema:"description=The programming language of the symbol (e.g., 'typescript', 'go'). Required if filePath is omitted."`
+	ShowLineNumbers bool   `json:"showLineNumbers" jsonschema:"default=true,description=Include line numbers in the returned source code"`
+	OutputFormatArgs
+}
+
+type ListCodeActionsArgs struct {
+	FilePath   string   `json:"filePath" jsonschema:"required,description=The path to the file to get code actions for"`
+	StartLine  int      `json:"startLine,omitempty" jsonschema:"description=1-based first line of the range. Defaults to 1."`
+	EndLine    int      `json:"endLine,omitempty" jsonschema:"description=1-based last line of the range, inclusive. Defaults to startLine."`
+	Diagnostic string   `json:"diagnostic,omitempty" jsonschema:"description=Only include fixes for diagnostics whose message or code contains this text (e.g. 'unused import')."`
+	Kinds      []string `json:"kinds,omitempty" jsonschema:"description=Only include actions of these kinds (e.g. 'quickfix', 'refactor.extract', 'source.organizeImports')."`
+	OutputFormatArgs
+}
+
+type ApplyCodeActionArgs struct {
+	FilePath   string   `json:"filePath" jsonschema:"required,description=The path to the file the code action applies to"`
+	StartLine  int      `json:"startLine,omitempty" jsonschema:"description=1-based first line of the range. Must match the list_code_actions call."`
+	EndLine    int      `json:"endLine,omitempty" jsonschema:"description=1-based last line of the range, inclusive. Must match the list_code_actions call."`
+	Diagnostic string   `json:"diagnostic,omitempty" jsonschema:"description=Diagnostic filter. Must match the list_code_actions call."`
+	Kinds      []string `json:"kinds,omitempty" jsonschema:"description=Kind filter. Must match the list_code_actions call."`
+	Index      int      `json:"index" jsonschema:"required,description=The index of the code action to apply (from list_code_actions output), 1 indexed"`
+	OutputFormatArgs
+}
+
+type FormatFileArgs struct {
+	FilePath  string `json:"filePath" jsonschema:"required,description=The path to the file to format"`
+	StartLine int    `json:"startLine,omitempty" jsonschema:"description=1-based first line to format. If omitted, the whole file is formatted."`
+	EndLine   int    `json:"endLine,omitempty" jsonschema:"description=1-based last line to format, inclusive. Defaults to startLine."`
+	OutputFormatArgs
+}
+
+type OrganizeImportsArgs struct {
+	FilePath string `json:"filePath,omitempty" jsonschema:"description=The path to the file to organize imports in. If omitted, every open file of language is processed."`
+	Language string `json:"language,omitempty" jsonschema:"description=The language whose open files should be processed when filePath is omitted."`
+	OutputFormatArgs
+}
+
+type SignatureHelpArgs struct {
+	FilePath string `json:"filePath" jsonschema:"required,description=The path to the file containing the call"`
+	Line     int    `json:"line" jsonschema:"required,description=1-based line number of a position inside the call's parentheses"`
+	Column   int    `json:"column" jsonschema:"required,description=1-based column of a position inside the call's parentheses, e.g. right after the opening parenthesis or a comma"`
+	OutputFormatArgs
+}
+
+type CompleteAtArgs struct {
+	FilePath string `json:"filePath" jsonschema:"required,description=The path to the file to complete in"`
+	Line     int    `json:"line" jsonschema:"required,description=1-based line number of the completion position"`
+	Column   int    `json:"column" jsonschema:"required,description=1-based column of the completion position"`
+	Prefix   string `json:"prefix,omitempty" jsonschema:"description=Text to insert at the position before completing, e.g. 'client.' to list the members of client. Only sent to the language server, the file on disk is never modified."`
+	Limit    int    `json:"limit,omitempty" jsonschema:"default=30,description=Maximum number of completion items to return."`
+	OutputFormatArgs
+}
+
+type OccurrencesInFileArgs struct {
+	FilePath   string `json:"filePath" jsonschema:"required,description=The path to the file to search in"`
+	Line       int    `json:"line,omitempty" jsonschema:"description=1-based line number of the symbol. Either line or symbolName is required."`
+	Column     int    `json:"column,omitempty" jsonschema:"description=1-based column of the symbol. Defaults to 1."`
+	SymbolName string `json:"symbolName,omitempty" jsonschema:"description=The name of a symbol declared in the file. Used when line is not provided."`
+	OutputFormatArgs
+}
+
+type ReadWithInlayHintsArgs struct {
+	FilePath     string `json:"filePath" jsonschema:"required,description=The path to the file to read"`
+	StartLine    int    `json:"startLine,omitempty" jsonschema:"description=1-based first line to read. Defaults to 1."`
+	EndLine      int    `json:"endLine,omitempty" jsonschema:"description=1-based last line to read, inclusive. Defaults to the end of the file."`
+	ShowTooltips bool   `json:"showTooltips,omitempty" jsonschema:"description=Resolve the hints and list their tooltips (e.g. full type information) below each line."`
+	OutputFormatArgs
+}
+
+type SemanticTokensArgs struct {
+	FilePath  string   `json:"filePath" jsonschema:"required,description=The path to the file to classify"`
+	StartLine int      `json:"startLine,omitempty" jsonschema:"description=1-based first line. Defaults to 1."`
+	EndLine   int      `json:"endLine,omitempty" jsonschema:"description=1-based last line, inclusive. Defaults to the end of the file."`
+	Mode      string   `json:"mode,omitempty" jsonschema:"enum=tokens,enum=deprecated,default=tokens,description=What to list: 'tokens' (classified tokens) or 'deprecated' (every use of a deprecated symbol)."`
+	Types     []string `json:"types,omitempty" jsonschema:"description=Only list tokens of these types (e.g. 'parameter', 'variable', 'method')."`
+	Modifiers []string `json:"modifiers,omitempty" jsonschema:"description=Only list tokens with all of these modifiers (e.g. 'readonly', 'deprecated', 'declaration')."`
+	OutputFormatArgs
+}
+
+type ReadEnclosingBlockArgs struct {
+	FilePath        string `json:"filePath" jsonschema:"required,description=The path to the file"`
+	Line            int    `json:"line,omitempty" jsonschema:"description=1-based line number of the position. Either line or symbolName is required."`
+	Column          int    `json:"column,omitempty" jsonschema:"description=1-based column of the position. Defaults to 1."`
+	SymbolName      string `json:"symbolName,omitempty" jsonschema:"description=The name of a symbol declared in the file. Used when line is not provided."`
+	Levels          int    `json:"levels,omitempty" jsonschema:"default=0,description=How many blocks to expand outward from the smallest one, e.g. 1 for the block containing the innermost block."`
+	ShowLineNumbers bool   `json:"showLineNumbers,omitempty" jsonschema:"default=true,description=Include line numbers in the returned code"`
+	OutputFormatArgs
+}
+
+type DocumentLinksArgs struct {
+	FilePath string `json:"filePath" jsonschema:"required,description=The path to the file to list links in"`
+	OutputFormatArgs
+}
+
+type WorkspaceDiagnosticsArgs struct {
+	Language    string `json:"language,omitempty" jsonschema:"description=Only report diagnostics from the language server for this language. Defaults to all language servers."`
+	Severity    string `json:"severity,omitempty" jsonschema:"enum=error,enum=warning,enum=info,enum=hint,description=Least severe level to include, e.g. 'warning' includes errors and warnings. Defaults to all levels."`
+	PathGlob    string `json:"pathGlob,omitempty" jsonschema:"description=Only include files whose workspace-relative or absolute path matches this glob. ** matches any number of directories, e.g. 'internal/**/*.go'."`
+	Source      string `json:"source,omitempty" jsonschema:"description=Only include diagnostics from this source, e.g. 'compiler' or 'eslint'."`
+	Code        string `json:"code,omitempty" jsonschema:"description=Only include diagnostics with this code."`
+	SummaryOnly bool   `json:"summaryOnly,omitempty" jsonschema:"default=false,description=If true, only the per-file counts are returned."`
+	OutputFormatArgs
+}
+
+type LanguageServerStatusArgs struct {
+	OutputFormatArgs
 }
 
 // Define args struct for find_symbols tool
@@ -118,6 +364,9 @@ type FindSymbolsArgs struct {
 	Scope           string `json:"scope" jsonschema:"required,enum=[\"workspace\", \"document\"],description=Search scope ('workspace' or 'document')."`
 	FilePath        string `json:"filePath,omitempty" jsonschema:"description=Path to the file (required if scope is 'document')."`
 	ShowLineNumbers bool   `json:"showLineNumbers,omitempty" jsonschema:"default=true,description=Include line numbers in the result."`
+	Language        string `json:"language,omitempty" jsonschema:"description=Only search the language server for this language (workspace scope). Defaults to all language servers."`
+	Kind            string `json:"kind,omitempty" jsonschema:"description=Only return symbols of this kind (e.g. 'Function' or 'Class')."`
+	OutputFormatArgs
 }
 
 
@@ -138,11 +387,16 @@ func (s *server) registerTools() error {
 			}
 
 			// Call the actual tool implementation with the selected client
-			response, err := internalTools.ApplyTextEdits(s.ctx, client, args.FilePath, args.Edits) // Use internalTools alias
+			var result *internalTools.EditResult
+			if args.ReportDiagnostics {
+				result, err = internalTools.ApplyTextEditsWithDiagnostics(s.ctx, client, args.FilePath, args.Edits)
+			} else {
+				result, err = internalTools.ApplyTextEdits(s.ctx, client, args.FilePath, args.Edits) // Use internalTools alias
+			}
 			if err != nil {
 				return nil, fmt.Errorf("failed to apply edits: %v", err)
 			}
-			return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(response)), nil
+			return s.renderResult(result, args.Format)
 		})
 	if err != nil {
 		return fmt.Errorf("failed to register tool: %v", err)
@@ -151,20 +405,20 @@ func (s *server) registerTools() error {
 	// Register read_definition tool
 	err = s.mcpServer.RegisterTool(
 		"read_definition",
-		"Read the source code definition of a symbol (function, type, constant, etc.) specified by `symbolName` and `language`. Returns the complete implementation code where the symbol is defined.", // Updated description
+		"Read the source code definition of a symbol (function, type, constant, etc.) specified by `symbolName`. Returns the complete implementation code where the symbol is defined. The request goes to the language server for `filePath` or `language` if given; otherwise every language server is queried in parallel and the definitions are merged and tagged with their language.",
 		func(args ReadDefinitionArgs) (*mcp_golang.ToolResponse, error) {
-			// Get LSP client based on language argument
-			client, ok := s.lspClients[args.Language]
-			if !ok {
-				return nil, fmt.Errorf("LSP client for language '%s' not found or not initialized", args.Language)
+			// Get LSP clients based on the filePath or language arguments
+			clients, err := s.getClientsForSymbol(args.FilePath, args.Language)
+			if err != nil {
+				return nil, err
 			}
 
-			// Call the actual tool implementation with the selected client
-			text, err := internalTools.ReadDefinition(s.ctx, client, args.SymbolName, args.ShowLineNumbers) // Use internalTools alias
+			// Call the actual tool implementation with the selected clients
+			result, err := internalTools.ReadDefinitionInLanguages(s.ctx, clients, args.SymbolName, args.ShowLineNumbers) // Use internalTools alias
 			if err != nil {
 				return nil, fmt.Errorf("failed to get definition: %v", err)
 			}
-			return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(text)), nil
+			return s.renderResult(result, args.Format)
 		})
 	if err != nil {
 		return fmt.Errorf("failed to register tool: %v", err)
@@ -173,20 +427,20 @@ func (s *server) registerTools() error {
 	// Register find_references tool
 	err = s.mcpServer.RegisterTool(
 		"find_references",
-		"Find all usages and references of a symbol specified by `symbolName` and `language` throughout the codebase. Returns a list of all files and locations where the symbol appears.", // Updated description
+		"Find all usages and references of a symbol specified by `symbolName` throughout the codebase. Returns a list of all files and locations where the symbol appears. The request goes to the language server for `filePath` or `language` if given; otherwise every language server is queried in parallel and the references are merged and tagged with their language.",
 		func(args FindReferencesArgs) (*mcp_golang.ToolResponse, error) {
-			// Get LSP client based on language argument
-			client, ok := s.lspClients[args.Language]
-			if !ok {
-				return nil, fmt.Errorf("LSP client for language '%s' not found or not initialized", args.Language)
+			// Get LSP clients based on the filePath or language arguments
+			clients, err := s.getClientsForSymbol(args.FilePath, args.Language)
+			if err != nil {
+				return nil, err
 			}
 
-			// Call the actual tool implementation with the selected client
-			text, err := internalTools.FindReferences(s.ctx, client, args.SymbolName, args.ShowLineNumbers) // Use internalTools alias
+			// Call the actual tool implementation with the selected clients
+			result, err := internalTools.FindReferencesInLanguages(s.ctx, clients, args.SymbolName, args.ShowLineNumbers) // Use internalTools alias
 			if err != nil {
 				return nil, fmt.Errorf("failed to find references: %v", err)
 			}
-			return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(text)), nil
+			return s.renderResult(result, args.Format)
 		})
 	if err != nil {
 		return fmt.Errorf("failed to register tool: %v", err)
@@ -204,11 +458,11 @@ func (s *server) registerTools() error {
 			}
 
 			// Call the actual tool implementation with the selected client
-			text, err := internalTools.GetDiagnosticsForFile(s.ctx, client, args.FilePath, args.IncludeContext, args.ShowLineNumbers) // Use internalTools alias
+			result, err := internalTools.GetDiagnosticsForFile(s.ctx, client, args.FilePath, args.IncludeContext, args.ShowLineNumbers) // Use internalTools alias
 			if err != nil {
 				return nil, fmt.Errorf("failed to get diagnostics: %v", err)
 			}
-			return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(text)), nil
+			return s.renderResult(result, args.Format)
 		},
 	)
 	if err != nil {
@@ -227,11 +481,11 @@ func (s *server) registerTools() error {
 			}
 
 			// Call the actual tool implementation with the selected client
-			text, err := internalTools.GetCodeLens(s.ctx, client, args.FilePath) // Use internalTools alias
+			result, err := internalTools.GetCodeLens(s.ctx, client, args.FilePath) // Use internalTools alias
 			if err != nil {
 				return nil, fmt.Errorf("failed to get code lens: %v", err)
 			}
-			return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(text)), nil
+			return s.renderResult(result, args.Format)
 		},
 	)
 	if err != nil {
@@ -250,11 +504,11 @@ func (s *server) registerTools() error {
 			}
 
 			// Call the actual tool implementation with the selected client
-			text, err := internalTools.ExecuteCodeLens(s.ctx, client, args.FilePath, args.Index) // Use internalTools alias
+			result, err := internalTools.ExecuteCodeLens(s.ctx, client, args.FilePath, args.Index) // Use internalTools alias
 			if err != nil {
 				return nil, fmt.Errorf("failed to execute code lens: %v", err)
 			}
-			return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(text)), nil
+			return s.renderResult(result, args.Format)
 		},
 	)
 	if err != nil {
@@ -264,10 +518,10 @@ func (s *server) registerTools() error {
 	// Register rename_symbol tool
 	err = s.mcpServer.RegisterTool(
 		"rename_symbol",
-		"Renames a symbol across the workspace using the Language Server Protocol.",
+		"Renames a symbol across the workspace using the Language Server Protocol. The symbol is addressed either by `filePath` with 0-based `line` and `character`, or by `symbolName` (optionally qualified, e.g. `MyType.MyMethod`); ambiguous names fail with a list of candidates. The changes, including any file renames, are written to disk. Set `dryRun` to get a unified diff per file without writing anything. Fails with a clear message if the server reports that the symbol at the position cannot be renamed.",
 		func(args RenameSymbolArgs) (*mcp_golang.ToolResponse, error) {
-			// Get LSP client based on file extension
-			client, err := s.getClientForFile(args.FilePath)
+			// Get LSP client based on file extension, or language for symbol names
+			client, err := s.getClientForFileOrLanguage(args.FilePath, args.Language)
 			if err != nil {
 				return nil, err
 			}
@@ -275,21 +529,19 @@ func (s *server) registerTools() error {
 			// Instantiate the tool struct (assuming it needs the client)
 			renameTool := internalTools.RenameSymbolTool{Client: client}
 
-			// Corrected: Marshal args to json.RawMessage before passing to Execute
-			argsJSON, err := json.Marshal(args)
-			if err != nil {
-				return nil, fmt.Errorf("failed to marshal rename args: %v", err)
-			}
-
-
 			// Execute the tool's logic
-			resultJSON, err := renameTool.Execute(s.ctx, argsJSON) // Pass marshaled JSON
+			result, err := renameTool.Rename(s.ctx, internalTools.RenameSymbolArgs{
+				FilePath:   args.FilePath,
+				Line:       args.Line,
+				Character:  args.Character,
+				SymbolName: args.SymbolName,
+				NewName:    args.NewName,
+				DryRun:     args.DryRun,
+			})
 			if err != nil {
 				return nil, fmt.Errorf("failed to execute rename symbol: %v", err)
 			}
-
-			// Corrected: Return result as text (string representation of the JSON)
-			return mcp_golang.NewToolResponse(mcp_golang.NewTextContent(string(resultJSON))), nil
+			return s.renderResult(result, args.Format)
 		},
 	)
 	if err != nil {
@@ -299,37 +551,44 @@ func (s *server) registerTools() error {
 	// Register find_symbols tool
 	err = s.mcpServer.RegisterTool(
 		"find_symbols",
-		"Finds symbols in the workspace or a specific document using the Language Server Protocol.",
+		"Finds symbols in the workspace or a specific document using the Language Server Protocol. Workspace searches query every language server, optionally only the one for `language`, and return the merged results tagged with their language, best matches first. Filter by symbol `kind`, e.g. `Function`.",
 		func(args FindSymbolsArgs) (*mcp_golang.ToolResponse, error) {
-			var client *lsp.Client
-			var err error
-
-			// Determine client based on scope
+			// Determine clients based on scope
+			var findTool internalTools.FindSymbolsTool
 			if args.Scope == "document" {
 				if args.FilePath == "" {
 					return nil, fmt.Errorf("filePath is required for document scope search")
 				}
-				client, err = s.getClientForFile(args.FilePath)
+				language, err := s.getLanguageForFile(args.FilePath)
 				if err != nil {
 					return nil, err
 				}
+				client, err := s.getClientForFile(args.FilePath)
+				if err != nil {
+					return nil, err
+				}
+				findTool = internalTools.FindSymbolsTool{Client: client, Language: language}
 			} else if args.Scope == "workspace" {
-				// For workspace scope, we might need a default client or iterate through all?
-				// Let's assume a primary client exists or pick the first one for now.
-				// This logic might need refinement based on how workspace symbols should work across languages.
-				if len(s.lspClients) == 0 {
-					return nil, fmt.Errorf("no LSP clients available for workspace symbol search")
+				// Workspace searches are sent to every language server and merged
+				clients := s.getAllClients()
+				if args.Language != "" {
+					client, err := s.getClientForLanguage(args.Language)
+					if err != nil {
+						return nil, err
+					}
+					clients = map[string]*lsp.Client{args.Language: client}
 				}
-				for _, c := range s.lspClients { // Just pick the first one
-					client = c
-					break
+				if len(clients) == 0 {
+					return nil, fmt.Errorf("no LSP clients available for workspace symbol search")
 				}
+				findTool = internalTools.FindSymbolsTool{Clients: clients}
 			} else {
 				return nil, fmt.Errorf("invalid scope: %s. Must be 'workspace' or 'document'", args.Scope)
 			}
 
-			// Instantiate the tool struct
-			findTool := internalTools.FindSymbolsTool{Client: client}
+			if args.Format == "" {
+				args.Format = s.config.OutputFormat
+			}
 
 			// Marshal args to json.RawMessage
 			argsJSON, err := json.Marshal(args)
@@ -351,6 +610,406 @@ func (s *server) registerTools() error {
 		return fmt.Errorf("failed to register find_symbols tool: %v", err)
 	}
 
+	// Register hover tool
+	err = s.mcpServer.RegisterTool(
+		"hover",
+		"Get the type signature and documentation of a symbol in the file specified by `filePath`, located either by `line`/`column` or by `symbolName`. Much cheaper than `read_definition` when only the signature is needed.",
+		func(args HoverArgs) (*mcp_golang.ToolResponse, error) {
+			// Get LSP client based on file extension
+			client, err := s.getClientForFile(args.FilePath)
+			if err != nil {
+				return nil, err
+			}
+
+			result, err := internalTools.GetHoverInfo(s.ctx, client, args.FilePath, args.Line, args.Column, args.SymbolName)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get hover information: %v", err)
+			}
+			return s.renderResult(result, args.Format)
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to register hover tool: %v", err)
+	}
+
+	// Register call_hierarchy tool
+	err = s.mcpServer.RegisterTool(
+		"call_hierarchy",
+		"Show the callers (`incoming`) and/or callees (`outgoing`) of a function as an indented tree up to `maxDepth` levels, with file:line for every call site. The function is located by `filePath` with `line`/`column`, or by `symbolName`. Use this for impact analysis before changing a function.",
+		func(args CallHierarchyArgs) (*mcp_golang.ToolResponse, error) {
+			client, err := s.getClientForFileOrLanguage(args.FilePath, args.Language)
+			if err != nil {
+				return nil, err
+			}
+
+			result, err := internalTools.GetCallHierarchy(s.ctx, client, args.FilePath, args.Line, args.Column, args.SymbolName, args.Direction, args.MaxDepth)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get call hierarchy: %v", err)
+			}
+			return s.renderResult(result, args.Format)
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to register call_hierarchy tool: %v", err)
+	}
+
+	// Register type_hierarchy tool
+	err = s.mcpServer.RegisterTool(
+		"type_hierarchy",
+		"Show the supertypes and/or subtypes of a type as an indented tree up to `maxDepth` levels, with the kind and location of every type. Use `subtypes` to find all implementations of an interface. The type is located by `filePath` with `line`/`column`, or by `symbolName`.",
+		func(args TypeHierarchyArgs) (*mcp_golang.ToolResponse, error) {
+			client, err := s.getClientForFileOrLanguage(args.FilePath, args.Language)
+			if err != nil {
+				return nil, err
+			}
+
+			result, err := internalTools.GetTypeHierarchy(s.ctx, client, args.FilePath, args.Line, args.Column, args.SymbolName, args.Direction, args.MaxDepth)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get type hierarchy: %v", err)
+			}
+			return s.renderResult(result, args.Format)
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to register type_hierarchy tool: %v", err)
+	}
+
+	// Register find_implementations tool
+	err = s.mcpServer.RegisterTool(
+		"find_implementations",
+		"Find every implementation of an interface, abstract method or type and return the complete code of each. The symbol is located by `filePath` with `line`/`column`, or by `symbolName`.",
+		func(args FindImplementationsArgs) (*mcp_golang.ToolResponse, error) {
+			client, err := s.getClientForFileOrLanguage(args.FilePath, args.Language)
+			if err != nil {
+				return nil, err
+			}
+
+			result, err := internalTools.FindImplementations(s.ctx, client, args.FilePath, args.Line, args.Column, args.SymbolName, args.ShowLineNumbers)
+			if err != nil {
+				return nil, fmt.Errorf("failed to find implementations: %v", err)
+			}
+			return s.renderResult(result, args.Format)
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to register find_implementations tool: %v", err)
+	}
+
+	// Register read_type_definition tool
+	err = s.mcpServer.RegisterTool(
+		"read_type_definition",
+		"Read the source code of the type of a variable, field or expression. The symbol is located by `filePath` with `line`/`column`, or by `symbolName`.",
+		func(args ReadTypeDefinitionArgs) (*mcp_golang.ToolResponse, error) {
+			client, err := s.getClientForFileOrLanguage(args.FilePath, args.Language)
+			if err != nil {
+				return nil, err
+			}
+
+			result, err := internalTools.ReadTypeDefinition(s.ctx, client, args.FilePath, args.Line, args.Column, args.SymbolName, args.ShowLineNumbers)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read type definition: %v", err)
+			}
+			return s.renderResult(result, args.Format)
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to register read_type_definition tool: %v", err)
+	}
+
+	// Register list_code_actions tool
+	err = s.mcpServer.RegisterTool(
+		"list_code_actions",
+		"List the quick fixes, refactorings and source actions the language server offers for a line range of the file specified by `filePath`, optionally filtered by `diagnostic` text and action `kinds`. Apply one with `apply_code_action`.",
+		func(args ListCodeActionsArgs) (*mcp_golang.ToolResponse, error) {
+			// Get LSP client based on file extension
+			client, err := s.getClientForFile(args.FilePath)
+			if err != nil {
+				return nil, err
+			}
+
+			result, err := internalTools.ListCodeActions(s.ctx, client, args.FilePath, args.StartLine, args.EndLine, args.Diagnostic, args.Kinds)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list code actions: %v", err)
+			}
+			return s.renderResult(result, args.Format)
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to register list_code_actions tool: %v", err)
+	}
+
+	// Register apply_code_action tool
+	err = s.mcpServer.RegisterTool(
+		"apply_code_action",
+		"Apply a code action (obtained from `list_code_actions`) by its `index`. Pass the same `filePath`, range and filters used to list it. The action's edits are written to disk and its command is executed.",
+		func(args ApplyCodeActionArgs) (*mcp_golang.ToolResponse, error) {
+			// Get LSP client based on file extension
+			client, err := s.getClientForFile(args.FilePath)
+			if err != nil {
+				return nil, err
+			}
+
+			result, err := internalTools.ApplyCodeAction(s.ctx, client, args.FilePath, args.StartLine, args.EndLine, args.Diagnostic, args.Kinds, args.Index)
+			if err != nil {
+				return nil, fmt.Errorf("failed to apply code action: %v", err)
+			}
+			return s.renderResult(result, args.Format)
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to register apply_code_action tool: %v", err)
+	}
+
+	// Register format_file tool
+	err = s.mcpServer.RegisterTool(
+		"format_file",
+		"Format the file specified by `filePath` with the language server's formatter (e.g. gofmt, prettier), or only the lines from `startLine` to `endLine`. The changes are written to disk and returned as a unified diff.",
+		func(args FormatFileArgs) (*mcp_golang.ToolResponse, error) {
+			language, err := s.getLanguageForFile(args.FilePath)
+			if err != nil {
+				return nil, err
+			}
+			client, err := s.getClientForFile(args.FilePath)
+			if err != nil {
+				return nil, err
+			}
+
+			result, err := internalTools.FormatFile(s.ctx, client, args.FilePath, args.StartLine, args.EndLine, s.getFormattingOptions(language))
+			if err != nil {
+				return nil, fmt.Errorf("failed to format file: %v", err)
+			}
+			return s.renderResult(result, args.Format)
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to register format_file tool: %v", err)
+	}
+
+	// Register organize_imports tool
+	err = s.mcpServer.RegisterTool(
+		"organize_imports",
+		"Sort, group and remove unused imports using the language server's organize imports action. Processes the file specified by `filePath`, or every file currently open in `language` if `filePath` is omitted. The changes are written to disk and returned as a unified diff per file.",
+		func(args OrganizeImportsArgs) (*mcp_golang.ToolResponse, error) {
+			client, err := s.getClientForFileOrLanguage(args.FilePath, args.Language)
+			if err != nil {
+				return nil, err
+			}
+
+			var filePaths []string
+			if args.FilePath != "" {
+				filePaths = []string{args.FilePath}
+			}
+
+			result, err := internalTools.OrganizeImports(s.ctx, client, filePaths)
+			if err != nil {
+				return nil, fmt.Errorf("failed to organize imports: %v", err)
+			}
+			return s.renderResult(result, args.Format)
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to register organize_imports tool: %v", err)
+	}
+
+	// Register signature_help tool
+	err = s.mcpServer.RegisterTool(
+		"signature_help",
+		"Show the signatures of the function or method being called at a position inside a call expression, as an editor does while typing arguments. Returns every overload, the active parameter and the documentation of each parameter.",
+		func(args SignatureHelpArgs) (*mcp_golang.ToolResponse, error) {
+			client, err := s.getClientForFile(args.FilePath)
+			if err != nil {
+				return nil, err
+			}
+
+			result, err := internalTools.GetSignatureHelp(s.ctx, client, args.FilePath, args.Line, args.Column)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get signature help: %v", err)
+			}
+			return s.renderResult(result, args.Format)
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to register signature_help tool: %v", err)
+	}
+
+	// Register complete_at tool
+	err = s.mcpServer.RegisterTool(
+		"complete_at",
+		"List the completions the language server offers at a position, with kind, detail and documentation, ranked as an editor would show them. Use `prefix` to complete as if text had been typed there, e.g. `client.` to discover the methods and fields of `client`. The file on disk is never modified.",
+		func(args CompleteAtArgs) (*mcp_golang.ToolResponse, error) {
+			client, err := s.getClientForFile(args.FilePath)
+			if err != nil {
+				return nil, err
+			}
+
+			result, err := internalTools.CompleteAt(s.ctx, client, args.FilePath, args.Line, args.Column, args.Prefix, args.Limit)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get completions: %v", err)
+			}
+			return s.renderResult(result, args.Format)
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to register complete_at tool: %v", err)
+	}
+
+	// Register occurrences_in_file tool
+	err = s.mcpServer.RegisterTool(
+		"occurrences_in_file",
+		"List every occurrence of a symbol within a single file, each classified as a read, write or text occurrence and shown with its line. A cheap, file-scoped alternative to `find_references`, e.g. to see where a local variable is assigned.",
+		func(args OccurrencesInFileArgs) (*mcp_golang.ToolResponse, error) {
+			client, err := s.getClientForFile(args.FilePath)
+			if err != nil {
+				return nil, err
+			}
+
+			result, err := internalTools.FindOccurrencesInFile(s.ctx, client, args.FilePath, args.Line, args.Column, args.SymbolName)
+			if err != nil {
+				return nil, fmt.Errorf("failed to find occurrences: %v", err)
+			}
+			return s.renderResult(result, args.Format)
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to register occurrences_in_file tool: %v", err)
+	}
+
+	// Register read_with_inlay_hints tool
+	err = s.mcpServer.RegisterTool(
+		"read_with_inlay_hints",
+		"Read a range of a file with the language server's inlay hints rendered inline between « and », as an IDE shows them: inferred types of variables (e.g. Go `:=`, TypeScript, Rust) and parameter names at call sites. The hints are not part of the file.",
+		func(args ReadWithInlayHintsArgs) (*mcp_golang.ToolResponse, error) {
+			client, err := s.getClientForFile(args.FilePath)
+			if err != nil {
+				return nil, err
+			}
+
+			result, err := internalTools.ReadWithInlayHints(s.ctx, client, args.FilePath, args.StartLine, args.EndLine, args.ShowTooltips)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read with inlay hints: %v", err)
+			}
+			return s.renderResult(result, args.Format)
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to register read_with_inlay_hints tool: %v", err)
+	}
+
+	// Register semantic_tokens tool
+	err = s.mcpServer.RegisterTool(
+		"semantic_tokens",
+		"Classify the identifiers of a file, or a line range of it, with the language server's semantic tokens: which are parameters, variables, methods, types and so on, and which are readonly, static, declarations or deprecated. Filter with `types` and `modifiers`, or set `mode` to 'deprecated' to list every use of a deprecated symbol.",
+		func(args SemanticTokensArgs) (*mcp_golang.ToolResponse, error) {
+			client, err := s.getClientForFile(args.FilePath)
+			if err != nil {
+				return nil, err
+			}
+
+			result, err := internalTools.GetSemanticTokens(s.ctx, client, args.FilePath, args.StartLine, args.EndLine, args.Mode, args.Types, args.Modifiers)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get semantic tokens: %v", err)
+			}
+			return s.renderResult(result, args.Format)
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to register semantic_tokens tool: %v", err)
+	}
+
+	// Register read_enclosing_block tool
+	err = s.mcpServer.RegisterTool(
+		"read_enclosing_block",
+		"Return the smallest syntactic block (loop, if statement, function, class, ...) spanning several lines around a position, as determined by the language server's parser. Use `levels` to expand further outward one block at a time. Works for indentation-based languages such as Python too.",
+		func(args ReadEnclosingBlockArgs) (*mcp_golang.ToolResponse, error) {
+			client, err := s.getClientForFile(args.FilePath)
+			if err != nil {
+				return nil, err
+			}
+
+			result, err := internalTools.ReadEnclosingBlock(s.ctx, client, args.FilePath, args.Line, args.Column, args.SymbolName, args.Levels, args.ShowLineNumbers)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read enclosing block: %v", err)
+			}
+			return s.renderResult(result, args.Format)
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to register read_enclosing_block tool: %v", err)
+	}
+
+	// Register document_links tool
+	err = s.mcpServer.RegisterTool(
+		"document_links",
+		"List every link the language server recognises in a file, each with its target: import paths resolved to files or documentation, URLs in comments, `#include` targets and so on. Useful to follow imports to concrete files without guessing the module layout.",
+		func(args DocumentLinksArgs) (*mcp_golang.ToolResponse, error) {
+			client, err := s.getClientForFile(args.FilePath)
+			if err != nil {
+				return nil, err
+			}
+
+			result, err := internalTools.GetDocumentLinks(s.ctx, client, args.FilePath)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get document links: %v", err)
+			}
+			return s.renderResult(result, args.Format)
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to register document_links tool: %v", err)
+	}
+
+	// Register get_workspace_diagnostics tool
+	err = s.mcpServer.RegisterTool(
+		"get_workspace_diagnostics",
+		"Get the diagnostics (errors, warnings, ...) of the whole workspace in one call, grouped by file with counts per file. Uses pull diagnostics where the language server supports them, and otherwise the diagnostics the server has published so far. Filter by severity, path glob, source and code.",
+		func(args WorkspaceDiagnosticsArgs) (*mcp_golang.ToolResponse, error) {
+			var clients []*lsp.Client
+			if args.Language != "" {
+				client, err := s.getClientForLanguage(args.Language)
+				if err != nil {
+					return nil, err
+				}
+				clients = append(clients, client)
+			} else {
+				running := s.getAllClients()
+				languages := make([]string, 0, len(running))
+				for language := range running {
+					languages = append(languages, language)
+				}
+				sort.Strings(languages)
+				for _, language := range languages {
+					clients = append(clients, running[language])
+				}
+			}
+
+			filter := internalTools.DiagnosticsFilter{
+				MinSeverity: args.Severity,
+				PathGlob:    args.PathGlob,
+				Source:      args.Source,
+				Code:        args.Code,
+			}
+			result, err := internalTools.GetWorkspaceDiagnostics(s.ctx, clients, s.config.WorkspaceDir, filter, args.SummaryOnly)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get workspace diagnostics: %v", err)
+			}
+			return s.renderResult(result, args.Format)
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to register get_workspace_diagnostics tool: %v", err)
+	}
+
+	// Register language_server_status tool
+	err = s.mcpServer.RegisterTool(
+		"language_server_status",
+		"Show the state of every configured language server: whether it is running, not started yet (lazy startup), restarting or failed, with its process ID, number of open files and how often it was restarted after exiting. A server that exits is restarted automatically with increasing delays and its open files are reopened; tool calls for its language fail while it restarts. A server that failed to start is started again by the next tool call for its language.",
+		func(args LanguageServerStatusArgs) (*mcp_golang.ToolResponse, error) {
+			return s.renderResult(s.getServerStatus(), args.Format)
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to register language_server_status tool: %v", err)
+	}
 
 	return nil
 }
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetFormattingOptions(t *testing.T) {
+	tests := []struct {
+		name       string
+		formatting string
+		want       protocol.FormattingOptions
+	}{
+		{"not configured", ``, protocol.FormattingOptions{TabSize: 4, InsertSpaces: true}},
+		{"empty", `"formatting": {}`, protocol.FormattingOptions{TabSize: 4, InsertSpaces: true}},
+		{"tab size only", `"formatting": {"tabSize": 2}`, protocol.FormattingOptions{TabSize: 2, InsertSpaces: true}},
+		{"tabs", `"formatting": {"insertSpaces": false}`, protocol.FormattingOptions{TabSize: 4, InsertSpaces: false}},
+		{"both", `"formatting": {"tabSize": 8, "insertSpaces": false}`, protocol.FormattingOptions{TabSize: 8, InsertSpaces: false}},
+		{"zero tab size", `"formatting": {"tabSize": 0}`, protocol.FormattingOptions{TabSize: 4, InsertSpaces: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := `{"language": "go", "command": "gopls"`
+			if tt.formatting != "" {
+				config += ", " + tt.formatting
+			}
+			config += "}"
+
+			var lsConfig LanguageServerConfig
+			require.NoError(t, json.Unmarshal([]byte(config), &lsConfig))
+			s := &server{config: Config{LanguageServers: []LanguageServerConfig{lsConfig}}}
+
+			assert.Equal(t, tt.want, s.getFormattingOptions("go"))
+		})
+	}
+
+	s := &server{}
+	assert.Equal(t, protocol.FormattingOptions{TabSize: 4, InsertSpaces: true}, s.getFormattingOptions("python"))
+}